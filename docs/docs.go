@@ -15,21 +15,194 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/apply": {
+        "/v1/audit": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Apply a coupon to a basket",
+                "description": "List the recorded coupon mutations matching the filters, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Coupons"
+                    "Audit"
                 ],
-                "summary": "Apply a coupon to a basket",
+                "summary": "List audit entries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user who made the change",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "status",
+                            "delete"
+                        ],
+                        "type": "string",
+                        "description": "Kind of change",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries, 100 by default and at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/reviewsch_internal_service_entity.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/campaigns": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create a campaign with a discount and redemption budget; its coupons pause once a budget is used up",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaigns"
+                ],
+                "summary": "Create a campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Campaign definition",
+                        "name": "campaign",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.Campaign"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Campaign"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/campaigns/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve a campaign with its current spend",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Campaigns"
+                ],
+                "summary": "Get a campaign",
                 "parameters": [
                     {
                         "type": "string",
@@ -37,31 +210,189 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.Basket"
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Campaign"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
                         }
                     }
                 }
             }
         },
         "/v1/coupons": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List coupons matching the filters, one page at a time; pass nextCursor as cursor to get the following page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "List coupons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "draft",
+                            "active",
+                            "paused",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "description": "Lifecycle status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "campaignId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "percentage",
+                            "fixed_amount",
+                            "free_shipping",
+                            "buy_x_get_y"
+                        ],
+                        "type": "string",
+                        "description": "Discount type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only coupons expiring before this time (RFC 3339)",
+                        "name": "expiresBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Code prefix",
+                        "name": "codePrefix",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "code",
+                            "expiresAt",
+                            "discount"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 200",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to get",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.CouponPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/": {
             "get": {
                 "security": [
                     {
@@ -92,39 +423,63 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "objects"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/v1/create": {
+        "/v1/coupons/applicable": {
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Create a new coupon",
+                "description": "Find every active coupon the basket and the calling customer qualify for, public or assigned to the customer, with the discount each would grant on its own, largest first",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Coupons"
                 ],
-                "summary": "Create a new coupon",
+                "summary": "List the coupons a basket qualifies for",
                 "parameters": [
                     {
                         "type": "string",
@@ -132,67 +487,3506 @@ const docTemplate = `{
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "Basket",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.ApplicableRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.SuccessResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/reviewsch_internal_service_entity.ApplicableCoupon"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
-        },
-        "api.SuccessResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
         },
-        "entity.Basket": {
-            "description": "Shopping basket with coupon application details",
-            "type": "object",
-            "properties": {
-                "applicationSuccessful": {
-                    "type": "boolean",
-                    "example": true
-                },
-                "appliedDiscount": {
-                    "type": "integer",
-                    "example": 10
-                },
-                "couponCode": {
-                    "type": "string",
-                    "example": "SUMMER2024"
-                },
-                "value": {
-                    "type": "number",
-                    "example": 100.5
+        "/v1/coupons/apply": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Apply a coupon to a basket",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Apply a coupon to a basket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key; retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Basket and coupon code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.ApplicationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Basket"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Personal coupon of another customer",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Coupon cannot be applied",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/apply-multiple": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Apply the combination of codes that gives the largest valid discount and explain why any code was dropped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Apply several coupons to a basket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key; retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Basket and coupon codes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.MultiApplicationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Application"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/create": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create a new coupon, optionally limited to a validity window",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Create a new coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key; retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Coupon definition",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.Coupon"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Coupon code already exists",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/generate": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Mint unique single-use codes from a template and download them as CSV",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Generate unique single-use coupons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key; retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Code format and coupon template",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.GenerateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV file with one generated code per line",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/mine": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the coupons assigned to the customer in the JWT that are active, unused and unexpired, those expiring first at the top",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "List the calling customer's personal coupons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/reviewsch_internal_service_entity.AssignedCoupon"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/recommend": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Pick the code or stackable set of codes giving the largest discount among the codes the customer holds, without applying them, and rank the other valid combinations by savings",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Recommend the best coupons for a basket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Basket and candidate coupon codes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.MultiApplicationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Recommendation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/reservations/{id}/commit": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Redeem a reserved coupon once the order is placed; only the customer who reserved it can commit it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Commit a coupon reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Reservation expired",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/reservations/{id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Free a reserved coupon when the order is cancelled and give its discount back to the campaign; only the customer who reserved it can release it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Release a coupon reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/reserve": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Apply a coupon to a basket, charge its campaign and hold the redemption until the order is committed or released",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Reserve a coupon for a basket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key; retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Basket and coupon code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.ApplicationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Basket"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Personal coupon of another customer",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Coupon cannot be applied",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/validate": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Run the apply checks without redeeming the coupon and return each check with the values it compared; the checks after the first one that failed are skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Check whether a coupon applies to a basket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Basket and coupon code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.ApplicationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Eligibility"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/versions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve the coupon terms named by the couponVersionId of an application result",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Get a coupon version by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon version ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.CouponVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve a coupon; the ETag header carries its version for updates",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Get a coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Coupon version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replace every term of a coupon; the change is based on the version in If-Match or in the body",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Replace the terms of a coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the coupon version being replaced",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New coupon terms",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.CouponUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New coupon version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Version in the body is not current",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match version is not current",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "No version given",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Remove a coupon; its redemptions are kept. The deletion is based on the version in If-Match or in the query",
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Delete a coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the coupon version being deleted",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Coupon version being deleted",
+                        "name": "version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Version in the query is not current",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match version is not current",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "No version given",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Change the terms present in the body and keep the others; the change is based on the version in If-Match or in the body",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Change some terms of a coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the coupon version being changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changed coupon terms",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.CouponUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New coupon version"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Version in the body is not current",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match version is not current",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "No version given",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Make a draft coupon applicable",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Activate a coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Stop a coupon from being applied for good; the coupon and its redemptions are kept",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Archive a coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}/as-of": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve the version of a coupon's terms in effect at the given time, e.g. when an order applied it; not found while the coupon was deleted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Get a coupon as of a time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Point in time (RFC 3339)",
+                        "name": "at",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.CouponVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}/diff": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the coupon fields that changed from one version to another",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Compare coupon versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Earlier version",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Later version",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.CouponDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Temporarily stop an active coupon from being applied, e.g. when its code leaked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Pause a coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Make a paused coupon applicable again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Resume a coupon",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict with the current state",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve every version of a coupon's terms, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "List coupon versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/reviewsch_internal_service_entity.CouponVersion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/coupons/{code}/versions/{version}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve a version of a coupon's terms by number",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coupons"
+                ],
+                "summary": "Get a coupon version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Coupon code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Coupon version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.CouponVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/rates": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve the exchange-rate table in effect now or at the given time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Get exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Point in time (RFC 3339)",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.RateTable"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Store a new version of the exchange-rate table; coupons applied from validFrom on are converted with it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Load exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Exchange rates",
+                        "name": "rates",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_api_dto_entity.RateTable"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.RateTable"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/rates/{version}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Retrieve a version of the exchange-rate table, e.g. the one reported with an applied coupon",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Get an exchange-rate version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer JWT token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rate table version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.RateTable"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_api_router.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "internal_api_router.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "coupon expired: expired at 2024-09-01T00:00:00Z"
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/coupons/apply"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "coupon_expired"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 422
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Unprocessable Entity"
+                },
+                "traceId": {
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:coupon-service:problem:coupon_expired"
+                }
+            }
+        },
+        "internal_api_router.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.ApplicableRequest": {
+            "type": "object",
+            "properties": {
+                "basket": {
+                    "$ref": "#/definitions/reviewsch_internal_api_dto_entity.Basket"
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.ApplicationRequest": {
+            "type": "object",
+            "properties": {
+                "basket": {
+                    "$ref": "#/definitions/reviewsch_internal_api_dto_entity.Basket"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.Basket": {
+            "description": "Shopping basket; amounts are decimals in the basket currency",
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_api_dto_entity.LineItem"
+                    }
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "shippingCost": {
+                    "type": "number",
+                    "example": 4.99
+                },
+                "value": {
+                    "type": "number",
+                    "example": 100.5
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.Campaign": {
+            "description": "Request to create a campaign with its budgets; a zero budget is unlimited",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "discountBudget": {
+                    "type": "number",
+                    "example": 5000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Summer sale"
+                },
+                "redemptionBudget": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1000
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.Coupon": {
+            "type": "object",
+            "required": [
+                "code",
+                "customerIds",
+                "minBasketValue"
+            ],
+            "properties": {
+                "amounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    },
+                    "example": {
+                        "PLN": 43
+                    }
+                },
+                "buyQuantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 2
+                },
+                "campaignId": {
+                    "type": "string",
+                    "example": "3b7e9c2a-1f4d-4c8e-9a6b-2d5f8e1c7a90"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "customerIds": {
+                    "description": "CustomerIDs makes the coupon personal to the listed customers",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "123"
+                    ]
+                },
+                "discount": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 10
+                },
+                "excludeCategories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sale"
+                    ]
+                },
+                "excludeSkus": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GIFT-CARD"
+                    ]
+                },
+                "exclusivityGroup": {
+                    "type": "string",
+                    "example": "seasonal"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2024-09-01T00:00:00Z"
+                },
+                "getQuantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "includeCategories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shoes"
+                    ]
+                },
+                "includeSkus": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SHOE-42-BLK"
+                    ]
+                },
+                "maxPerCustomer": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "maxRedemptions": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1000
+                },
+                "minBasketValue": {
+                    "type": "number",
+                    "example": 50.3
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "customer.new \u0026\u0026 basket.categories contains 'shoes'"
+                },
+                "stackable": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "startsAt": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "active"
+                    ],
+                    "example": "draft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "percentage",
+                        "fixed_amount",
+                        "free_shipping",
+                        "buy_x_get_y"
+                    ],
+                    "example": "percentage"
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.CouponTerms": {
+            "type": "object",
+            "required": [
+                "customerIds",
+                "minBasketValue"
+            ],
+            "properties": {
+                "amounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    },
+                    "example": {
+                        "PLN": 43
+                    }
+                },
+                "buyQuantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 2
+                },
+                "campaignId": {
+                    "type": "string",
+                    "example": "3b7e9c2a-1f4d-4c8e-9a6b-2d5f8e1c7a90"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "customerIds": {
+                    "description": "CustomerIDs makes the coupon personal to the listed customers",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "123"
+                    ]
+                },
+                "discount": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 10
+                },
+                "excludeCategories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sale"
+                    ]
+                },
+                "excludeSkus": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GIFT-CARD"
+                    ]
+                },
+                "exclusivityGroup": {
+                    "type": "string",
+                    "example": "seasonal"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2024-09-01T00:00:00Z"
+                },
+                "getQuantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "includeCategories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shoes"
+                    ]
+                },
+                "includeSkus": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SHOE-42-BLK"
+                    ]
+                },
+                "maxPerCustomer": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "maxRedemptions": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1000
+                },
+                "minBasketValue": {
+                    "type": "number",
+                    "example": 50.3
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "customer.new \u0026\u0026 basket.categories contains 'shoes'"
+                },
+                "stackable": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "startsAt": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "active"
+                    ],
+                    "example": "draft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "percentage",
+                        "fixed_amount",
+                        "free_shipping",
+                        "buy_x_get_y"
+                    ],
+                    "example": "percentage"
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.CouponUpdate": {
+            "type": "object",
+            "required": [
+                "customerIds",
+                "minBasketValue"
+            ],
+            "properties": {
+                "amounts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    },
+                    "example": {
+                        "PLN": 43
+                    }
+                },
+                "buyQuantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 2
+                },
+                "campaignId": {
+                    "type": "string",
+                    "example": "3b7e9c2a-1f4d-4c8e-9a6b-2d5f8e1c7a90"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "customerIds": {
+                    "description": "CustomerIDs makes the coupon personal to the listed customers",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "123"
+                    ]
+                },
+                "discount": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 10
+                },
+                "excludeCategories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sale"
+                    ]
+                },
+                "excludeSkus": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GIFT-CARD"
+                    ]
+                },
+                "exclusivityGroup": {
+                    "type": "string",
+                    "example": "seasonal"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2024-09-01T00:00:00Z"
+                },
+                "getQuantity": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "includeCategories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "shoes"
+                    ]
+                },
+                "includeSkus": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SHOE-42-BLK"
+                    ]
+                },
+                "maxPerCustomer": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "maxRedemptions": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1000
+                },
+                "minBasketValue": {
+                    "type": "number",
+                    "example": 50.3
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rule": {
+                    "type": "string",
+                    "example": "customer.new \u0026\u0026 basket.categories contains 'shoes'"
+                },
+                "stackable": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "startsAt": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "draft",
+                        "active"
+                    ],
+                    "example": "draft"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "percentage",
+                        "fixed_amount",
+                        "free_shipping",
+                        "buy_x_get_y"
+                    ],
+                    "example": "percentage"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.ExchangeRate": {
+            "type": "object",
+            "required": [
+                "from",
+                "rate",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "rate": {
+                    "type": "number",
+                    "example": 4.3125
+                },
+                "to": {
+                    "type": "string",
+                    "example": "PLN"
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.GenerateRequest": {
+            "description": "Request to generate unique single-use codes sharing the template terms",
+            "type": "object",
+            "required": [
+                "count"
+            ],
+            "properties": {
+                "alphabet": {
+                    "type": "string",
+                    "example": "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+                },
+                "avoidAmbiguous": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "count": {
+                    "type": "integer",
+                    "maximum": 100000,
+                    "minimum": 1,
+                    "example": 1000
+                },
+                "length": {
+                    "type": "integer",
+                    "maximum": 32,
+                    "minimum": 4,
+                    "example": 8
+                },
+                "prefix": {
+                    "type": "string",
+                    "maxLength": 16,
+                    "example": "SUMMER-"
+                },
+                "template": {
+                    "$ref": "#/definitions/reviewsch_internal_api_dto_entity.CouponTerms"
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.LineItem": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "shoes"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "SHOE-42-BLK"
+                },
+                "unitPrice": {
+                    "type": "number",
+                    "example": 33.5
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.MultiApplicationRequest": {
+            "type": "object",
+            "required": [
+                "codes"
+            ],
+            "properties": {
+                "basket": {
+                    "$ref": "#/definitions/reviewsch_internal_api_dto_entity.Basket"
+                },
+                "codes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "SUMMER2024",
+                        "FREESHIP"
+                    ]
+                }
+            }
+        },
+        "reviewsch_internal_api_dto_entity.RateTable": {
+            "description": "Exchange rates taking effect at validFrom, or immediately when it is omitted",
+            "type": "object",
+            "required": [
+                "rates"
+            ],
+            "properties": {
+                "rates": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_api_dto_entity.ExchangeRate"
+                    }
+                },
+                "validFrom": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.ApplicableCoupon": {
+            "description": "Coupon the basket qualifies for, with the discount applying it on its own would grant",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                },
+                "discount": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "discountAmount": {
+                    "type": "number",
+                    "example": 10.05
+                },
+                "exchangeRate": {
+                    "$ref": "#/definitions/reviewsch_internal_service_entity.ExchangeRate"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2024-09-01T00:00:00Z"
+                },
+                "finalValue": {
+                    "type": "number",
+                    "example": 95.44
+                },
+                "stackable": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.DiscountType"
+                        }
+                    ],
+                    "example": "percentage"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.Application": {
+            "description": "Basket with the coupon combination that was applied and the codes that were dropped",
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.AppliedCoupon"
+                    }
+                },
+                "basket": {
+                    "$ref": "#/definitions/reviewsch_internal_service_entity.Basket"
+                },
+                "dropped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.DroppedCoupon"
+                    }
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.AppliedCoupon": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                },
+                "discountAmount": {
+                    "type": "number",
+                    "example": 10.05
+                },
+                "exchangeRate": {
+                    "$ref": "#/definitions/reviewsch_internal_service_entity.ExchangeRate"
+                },
+                "versionId": {
+                    "type": "string",
+                    "example": "6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60-v3"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.AssignedCoupon": {
+            "description": "Personal coupon assigned to the customer that has not been used and has not expired",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "WELCOME-BACK-7Q2X"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "discount": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2024-09-01T00:00:00Z"
+                },
+                "minBasketValue": {
+                    "type": "number",
+                    "example": 50
+                },
+                "stackable": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "startsAt": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.DiscountType"
+                        }
+                    ],
+                    "example": "percentage"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.AuditAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "status",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "AuditCreate",
+                "AuditUpdate",
+                "AuditStatus",
+                "AuditDelete"
+            ]
+        },
+        "reviewsch_internal_service_entity.AuditEntry": {
+            "description": "Who changed a coupon, when, in which request and which fields changed",
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.AuditAction"
+                        }
+                    ],
+                    "example": "update"
+                },
+                "actorId": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "actorRole": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "at": {
+                    "type": "string",
+                    "example": "2024-06-01T12:00:00Z"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.FieldChange"
+                    }
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60"
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.Basket": {
+            "description": "Shopping basket with coupon application details",
+            "type": "object",
+            "properties": {
+                "applicationSuccessful": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "appliedDiscount": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "couponCode": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                },
+                "couponVersionId": {
+                    "type": "string",
+                    "example": "6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60-v3"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "discountAmount": {
+                    "type": "number",
+                    "example": 10.05
+                },
+                "exchangeRate": {
+                    "description": "ExchangeRate is the rate used to convert the coupon amounts into the\nbasket currency, if they had to be converted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/reviewsch_internal_service_entity.ExchangeRate"
+                        }
+                    ]
+                },
+                "finalValue": {
+                    "type": "number",
+                    "example": 95.44
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.LineItem"
+                    }
+                },
+                "originalValue": {
+                    "type": "number",
+                    "example": 100.5
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "reservationId": {
+                    "type": "string",
+                    "example": "6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60"
+                },
+                "reservedUntil": {
+                    "type": "string",
+                    "example": "2024-06-01T12:15:00Z"
+                },
+                "shippingCost": {
+                    "type": "number",
+                    "example": 4.99
+                },
+                "value": {
+                    "type": "number",
+                    "example": 100.5
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.Campaign": {
+            "description": "Promotion owning many coupons with a discount and redemption budget",
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "discountBudget": {
+                    "type": "number",
+                    "example": 5000
+                },
+                "discountSpent": {
+                    "type": "number",
+                    "example": 1250.4
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3b7e9c2a-1f4d-4c8e-9a6b-2d5f8e1c7a90"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Summer sale"
+                },
+                "paused": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "redemptionBudget": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "redemptions": {
+                    "type": "integer",
+                    "example": 231
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.Coupon": {
+            "description": "Discount coupon",
+            "type": "object",
+            "properties": {
+                "amounts": {
+                    "description": "Amounts are fixed amount discounts by currency for other currencies\nthan the coupon currency. Without one the discount is converted with\nthe exchange rate in effect when the coupon is applied.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "buyQuantity": {
+                    "type": "integer"
+                },
+                "campaignID": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customerIDs": {
+                    "description": "CustomerIDs makes the coupon personal: only the listed customers can\napply it. A coupon without any is public.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "discount": {
+                    "type": "integer"
+                },
+                "excludeCategories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "excludeSKUs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "exclusivityGroup": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "getQuantity": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "includeCategories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "includeSKUs": {
+                    "description": "Targeting restricts the discount to matching basket lines. When both\ninclude lists are empty every line that is not excluded is eligible.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "maxPerCustomer": {
+                    "type": "integer"
+                },
+                "maxRedemptions": {
+                    "type": "integer"
+                },
+                "minBasketValue": {
+                    "type": "number"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "rule": {
+                    "description": "Rule is an optional eligibility expression evaluated against the\nbasket and the customer, see package rule.",
+                    "type": "string"
+                },
+                "stackable": {
+                    "description": "Stacking rules decide which coupons can be applied together. Only\nstackable coupons combine, at most one per exclusivity group, and\ncoupons with a higher priority are applied first.",
+                    "type": "boolean"
+                },
+                "startsAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/reviewsch_internal_service_entity.CouponStatus"
+                },
+                "type": {
+                    "$ref": "#/definitions/reviewsch_internal_service_entity.DiscountType"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.CouponDiff": {
+            "description": "Fields whose value differs between version from and version to",
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.FieldChange"
+                    }
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.CouponPage": {
+            "description": "Page of coupons; pass nextCursor to get the following page",
+            "type": "object",
+            "properties": {
+                "coupons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string",
+                    "example": "eyJjIjoiU1VNTUVSMjAyNCJ9"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.CouponSet": {
+            "type": "object",
+            "properties": {
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "TEN"
+                    ]
+                },
+                "finalValue": {
+                    "type": "number",
+                    "example": 94.99
+                },
+                "savings": {
+                    "type": "number",
+                    "example": 10
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.CouponStatus": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "active",
+                "paused",
+                "archived"
+            ],
+            "x-enum-varnames": [
+                "CouponDraft",
+                "CouponActive",
+                "CouponPaused",
+                "CouponArchived"
+            ]
+        },
+        "reviewsch_internal_service_entity.CouponVersion": {
+            "description": "Coupon terms as they were from recordedAt until the next version",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                },
+                "coupon": {
+                    "$ref": "#/definitions/reviewsch_internal_service_entity.Coupon"
+                },
+                "deleted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60-v3"
+                },
+                "recordedAt": {
+                    "type": "string",
+                    "example": "2024-06-01T12:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.DiscountType": {
+            "type": "string",
+            "enum": [
+                "percentage",
+                "fixed_amount",
+                "free_shipping",
+                "buy_x_get_y"
+            ],
+            "x-enum-varnames": [
+                "DiscountPercentage",
+                "DiscountFixedAmount",
+                "DiscountFreeShipping",
+                "DiscountBuyXGetY"
+            ]
+        },
+        "reviewsch_internal_service_entity.DroppedCoupon": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "WINTER2024"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "coupon not combinable: coupon SUMMER2024 cannot be combined with other coupons"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "coupon_not_combinable"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.Eligibility": {
+            "description": "Every check applying the coupon goes through, in order; the checks after the first one that failed are skipped",
+            "type": "object",
+            "properties": {
+                "basket": {
+                    "$ref": "#/definitions/reviewsch_internal_service_entity.Basket"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.EligibilityCheck"
+                    }
+                },
+                "code": {
+                    "type": "string",
+                    "example": "SUMMER2024"
+                },
+                "eligible": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "below_min_basket_value"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.EligibilityCheck": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "string",
+                    "example": "40.00 EUR"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "basket value below coupon minimum: got 40.00, want minimum 50.00"
+                },
+                "expected": {
+                    "type": "string",
+                    "example": "\u003e= 50.00 EUR"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "min_basket"
+                },
+                "passed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "skipped": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.ExchangeRate": {
+            "description": "Exchange rate; one unit of the from currency costs rate units of the to currency",
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "4.3125"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "PLN"
+                },
+                "validFrom": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.FieldChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "string",
+                    "example": "15"
+                },
+                "before": {
+                    "type": "string",
+                    "example": "10"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "Discount"
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.LineItem": {
+            "description": "Basket line with the share of the coupon discount allocated to it",
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "shoes"
+                },
+                "discount": {
+                    "type": "number",
+                    "example": 10.05
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "sku": {
+                    "type": "string",
+                    "example": "SHOE-42-BLK"
+                },
+                "unitPrice": {
+                    "type": "number",
+                    "example": 33.5
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.RateTable": {
+            "description": "Versioned set of exchange rates",
+            "type": "object",
+            "properties": {
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.ExchangeRate"
+                    }
+                },
+                "validFrom": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "reviewsch_internal_service_entity.Recommendation": {
+            "description": "Codes giving the largest discount, the basket they produce and the other valid combinations ranked by savings",
+            "type": "object",
+            "properties": {
+                "alternatives": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.CouponSet"
+                    }
+                },
+                "basket": {
+                    "$ref": "#/definitions/reviewsch_internal_service_entity.Basket"
+                },
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "TEN",
+                        "FREESHIP"
+                    ]
+                },
+                "dropped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reviewsch_internal_service_entity.DroppedCoupon"
+                    }
+                },
+                "savings": {
+                    "type": "number",
+                    "example": 14.99
                 }
             }
         }
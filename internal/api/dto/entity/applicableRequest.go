@@ -0,0 +1,6 @@
+package entity
+
+// ApplicableRequest represents the request for the coupons a basket qualifies for
+type ApplicableRequest struct {
+	Basket Basket `json:"basket"`
+}
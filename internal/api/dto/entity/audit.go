@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"reviewsch/internal/service/entity"
+	"time"
+)
+
+// AuditListRequest represents the filters of an audit log listing
+type AuditListRequest struct {
+	Code   string     `form:"code"`
+	Actor  string     `form:"actor"`
+	Action string     `form:"action" binding:"omitempty,oneof=create update status delete"`
+	From   *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit  int        `form:"limit" binding:"gte=0,lte=1000"`
+}
+
+// ToEntity converts the request into a service audit query
+func (r AuditListRequest) ToEntity() entity.AuditQuery {
+	query := entity.AuditQuery{
+		Code:    r.Code,
+		ActorID: r.Actor,
+		Action:  entity.AuditAction(r.Action),
+		Limit:   r.Limit,
+	}
+	if r.From != nil {
+		query.From = *r.From
+	}
+	if r.To != nil {
+		query.To = *r.To
+	}
+	return query
+}
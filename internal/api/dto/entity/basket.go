@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"reviewsch/internal/service/entity"
+)
+
+// Basket represents a shopping basket sent by the client. Amounts are
+// decimals in major units and are converted to exact minor units.
+// @Description Shopping basket; amounts are decimals in the basket currency
+type Basket struct {
+	Currency     string      `json:"currency" binding:"omitempty,len=3" example:"EUR"`
+	Value        json.Number `json:"value" swaggertype:"number" example:"100.50"`
+	ShippingCost json.Number `json:"shippingCost" swaggertype:"number" example:"4.99"`
+	Quantity     int         `json:"quantity" example:"3"`
+	Items        []LineItem  `json:"items,omitempty"`
+}
+
+// LineItem represents a product line in a basket
+type LineItem struct {
+	SKU       string      `json:"sku" example:"SHOE-42-BLK"`
+	Category  string      `json:"category" example:"shoes"`
+	UnitPrice json.Number `json:"unitPrice" swaggertype:"number" example:"33.50"`
+	Quantity  int         `json:"quantity" example:"3"`
+}
+
+// ToEntity converts the request into a service basket
+func (b Basket) ToEntity() (entity.Basket, error) {
+	currency := b.Currency
+	if currency == "" {
+		currency = entity.DefaultCurrency
+	}
+
+	basket := entity.Basket{Currency: currency, Quantity: b.Quantity}
+	var err error
+	if basket.Value, err = parseAmount(b.Value, currency); err != nil {
+		return entity.Basket{}, fmt.Errorf("value: %w", err)
+	}
+	if basket.ShippingCost, err = parseAmount(b.ShippingCost, currency); err != nil {
+		return entity.Basket{}, fmt.Errorf("shippingCost: %w", err)
+	}
+
+	for _, item := range b.Items {
+		unitPrice, err := parseAmount(item.UnitPrice, currency)
+		if err != nil {
+			return entity.Basket{}, fmt.Errorf("item %q: unitPrice: %w", item.SKU, err)
+		}
+		basket.Items = append(basket.Items, entity.LineItem{
+			SKU:       item.SKU,
+			Category:  item.Category,
+			UnitPrice: unitPrice,
+			Quantity:  item.Quantity,
+		})
+	}
+	return basket, nil
+}
+
+// parseAmount converts an optional decimal amount into money
+func parseAmount(amount json.Number, currency string) (entity.Money, error) {
+	if amount == "" {
+		return entity.Money{Currency: currency}, nil
+	}
+	return entity.ParseMoney(amount.String(), currency)
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"reviewsch/internal/service/entity"
+)
+
+// Campaign represents a request to create a campaign
+// @Description Request to create a campaign with its budgets; a zero budget is unlimited
+type Campaign struct {
+	Name             string      `json:"name" binding:"required" example:"Summer sale"`
+	Currency         string      `json:"currency" binding:"omitempty,len=3" example:"EUR"`
+	DiscountBudget   json.Number `json:"discountBudget" swaggertype:"number" example:"5000"`
+	RedemptionBudget int         `json:"redemptionBudget" binding:"gte=0" example:"1000"`
+}
+
+// ToEntity converts the request into a service campaign
+func (c Campaign) ToEntity() (entity.Campaign, error) {
+	currency := c.Currency
+	if currency == "" {
+		currency = entity.DefaultCurrency
+	}
+	budget, err := parseAmount(c.DiscountBudget, currency)
+	if err != nil {
+		return entity.Campaign{}, fmt.Errorf("discountBudget: %w", err)
+	}
+
+	return entity.Campaign{
+		Name:             c.Name,
+		Currency:         currency,
+		DiscountBudget:   budget,
+		RedemptionBudget: c.RedemptionBudget,
+	}, nil
+}
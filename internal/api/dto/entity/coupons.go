@@ -1,8 +1,164 @@
 package entity
 
+import (
+	"encoding/json"
+	"fmt"
+	"reviewsch/internal/service/entity"
+	"time"
+)
+
 // Coupon represents a discount coupon
 type Coupon struct {
-	Code           string  `json:"code" binding:"required" example:"SUMMER2024"`
-	Discount       int     `json:"discount" binding:"required" example:"10"`
-	MinBasketValue float64 `json:"minBasketValue" binding:"required" example:"50.3"`
+	Code string `json:"code" binding:"required" example:"SUMMER2024"`
+	CouponTerms
+}
+
+// CouponUpdate represents a request to replace or change the terms of a
+// coupon. The version is only needed without an If-Match header.
+type CouponUpdate struct {
+	Version int `json:"version,omitempty" example:"3"`
+	CouponTerms
+}
+
+// CouponTerms holds the discount settings of a coupon without its code
+type CouponTerms struct {
+	Type           string                 `json:"type" binding:"omitempty,oneof=percentage fixed_amount free_shipping buy_x_get_y" example:"percentage"`
+	Discount       int                    `json:"discount" binding:"gte=0" example:"10"`
+	Status         string                 `json:"status" binding:"omitempty,oneof=draft active" example:"draft"`
+	Currency       string                 `json:"currency" binding:"omitempty,len=3" example:"EUR"`
+	MinBasketValue json.Number            `json:"minBasketValue" binding:"required" swaggertype:"number" example:"50.3"`
+	Amounts        map[string]json.Number `json:"amounts,omitempty" swaggertype:"object,number" example:"PLN:43"`
+	BuyQuantity    int                    `json:"buyQuantity" binding:"gte=0" example:"2"`
+	GetQuantity    int                    `json:"getQuantity" binding:"gte=0" example:"1"`
+	StartsAt       *time.Time             `json:"startsAt,omitempty" example:"2024-06-01T00:00:00Z"`
+	ExpiresAt      *time.Time             `json:"expiresAt,omitempty" example:"2024-09-01T00:00:00Z"`
+	MaxRedemptions int                    `json:"maxRedemptions" binding:"gte=0" example:"1000"`
+	MaxPerCustomer int                    `json:"maxPerCustomer" binding:"gte=0" example:"1"`
+
+	IncludeSKUs       []string `json:"includeSkus,omitempty" example:"SHOE-42-BLK"`
+	ExcludeSKUs       []string `json:"excludeSkus,omitempty" example:"GIFT-CARD"`
+	IncludeCategories []string `json:"includeCategories,omitempty" example:"shoes"`
+	ExcludeCategories []string `json:"excludeCategories,omitempty" example:"sale"`
+
+	Stackable        bool   `json:"stackable" example:"true"`
+	ExclusivityGroup string `json:"exclusivityGroup,omitempty" example:"seasonal"`
+	Priority         int    `json:"priority" example:"1"`
+
+	CampaignID string `json:"campaignId,omitempty" example:"3b7e9c2a-1f4d-4c8e-9a6b-2d5f8e1c7a90"`
+	Rule       string `json:"rule,omitempty" example:"customer.new && basket.categories contains 'shoes'"`
+
+	// CustomerIDs makes the coupon personal to the listed customers
+	CustomerIDs []string `json:"customerIds,omitempty" binding:"omitempty,dive,required" example:"123"`
+}
+
+// ToEntity converts the request into a service coupon
+func (c Coupon) ToEntity() (entity.Coupon, error) {
+	coupon, err := c.CouponTerms.ToEntity()
+	if err != nil {
+		return entity.Coupon{}, err
+	}
+	coupon.Code = c.Code
+	return coupon, nil
+}
+
+// ToEntity converts the terms into a service coupon without a code
+func (c CouponTerms) ToEntity() (entity.Coupon, error) {
+	currency := c.Currency
+	if currency == "" {
+		currency = entity.DefaultCurrency
+	}
+	minimum, err := parseAmount(c.MinBasketValue, currency)
+	if err != nil {
+		return entity.Coupon{}, fmt.Errorf("minBasketValue: %w", err)
+	}
+
+	var amounts map[string]entity.Money
+	for code, amount := range c.Amounts {
+		money, err := entity.ParseMoney(amount.String(), code)
+		if err != nil {
+			return entity.Coupon{}, fmt.Errorf("amounts: %s: %w", code, err)
+		}
+		if amounts == nil {
+			amounts = make(map[string]entity.Money, len(c.Amounts))
+		}
+		amounts[code] = money
+	}
+
+	coupon := entity.Coupon{
+		Type:           entity.DiscountType(c.Type),
+		Status:         entity.CouponStatus(c.Status),
+		Discount:       c.Discount,
+		Currency:       currency,
+		MinBasketValue: minimum,
+		Amounts:        amounts,
+		BuyQuantity:    c.BuyQuantity,
+		GetQuantity:    c.GetQuantity,
+		MaxRedemptions: c.MaxRedemptions,
+		MaxPerCustomer: c.MaxPerCustomer,
+
+		IncludeSKUs:       c.IncludeSKUs,
+		ExcludeSKUs:       c.ExcludeSKUs,
+		IncludeCategories: c.IncludeCategories,
+		ExcludeCategories: c.ExcludeCategories,
+
+		Stackable:        c.Stackable,
+		ExclusivityGroup: c.ExclusivityGroup,
+		Priority:         c.Priority,
+
+		CampaignID: c.CampaignID,
+		Rule:       c.Rule,
+
+		CustomerIDs: c.CustomerIDs,
+	}
+	if c.StartsAt != nil {
+		coupon.StartsAt = *c.StartsAt
+	}
+	if c.ExpiresAt != nil {
+		coupon.ExpiresAt = *c.ExpiresAt
+	}
+	return coupon, nil
+}
+
+// NewCouponTerms converts the terms of a service coupon back into a request,
+// so a partial update can be applied on top of them
+func NewCouponTerms(coupon entity.Coupon) CouponTerms {
+	terms := CouponTerms{
+		Type:           string(coupon.Type),
+		Discount:       coupon.Discount,
+		Currency:       coupon.Currency,
+		MinBasketValue: json.Number(coupon.MinBasketValue.String()),
+		BuyQuantity:    coupon.BuyQuantity,
+		GetQuantity:    coupon.GetQuantity,
+		MaxRedemptions: coupon.MaxRedemptions,
+		MaxPerCustomer: coupon.MaxPerCustomer,
+
+		IncludeSKUs:       coupon.IncludeSKUs,
+		ExcludeSKUs:       coupon.ExcludeSKUs,
+		IncludeCategories: coupon.IncludeCategories,
+		ExcludeCategories: coupon.ExcludeCategories,
+
+		Stackable:        coupon.Stackable,
+		ExclusivityGroup: coupon.ExclusivityGroup,
+		Priority:         coupon.Priority,
+
+		CampaignID: coupon.CampaignID,
+		Rule:       coupon.Rule,
+
+		CustomerIDs: coupon.CustomerIDs,
+	}
+	for code, amount := range coupon.Amounts {
+		if terms.Amounts == nil {
+			terms.Amounts = make(map[string]json.Number, len(coupon.Amounts))
+		}
+		terms.Amounts[code] = json.Number(amount.String())
+	}
+	if !coupon.StartsAt.IsZero() {
+		startsAt := coupon.StartsAt
+		terms.StartsAt = &startsAt
+	}
+	if !coupon.ExpiresAt.IsZero() {
+		expiresAt := coupon.ExpiresAt
+		terms.ExpiresAt = &expiresAt
+	}
+	return terms
 }
@@ -0,0 +1,25 @@
+package entity
+
+import "reviewsch/internal/service/entity"
+
+// GenerateRequest represents a request to mint single-use coupon codes
+// @Description Request to generate unique single-use codes sharing the template terms
+type GenerateRequest struct {
+	Count          int         `json:"count" binding:"required,min=1,max=100000" example:"1000"`
+	Prefix         string      `json:"prefix" binding:"max=16" example:"SUMMER-"`
+	Length         int         `json:"length" binding:"omitempty,min=4,max=32" example:"8"`
+	Alphabet       string      `json:"alphabet,omitempty" example:"ABCDEFGHJKMNPQRSTUVWXYZ23456789"`
+	AvoidAmbiguous bool        `json:"avoidAmbiguous" example:"true"`
+	Template       CouponTerms `json:"template"`
+}
+
+// CodeSpec converts the request into the service code specification
+func (r GenerateRequest) CodeSpec() entity.CodeSpec {
+	return entity.CodeSpec{
+		Count:          r.Count,
+		Prefix:         r.Prefix,
+		Length:         r.Length,
+		Alphabet:       r.Alphabet,
+		AvoidAmbiguous: r.AvoidAmbiguous,
+	}
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"reviewsch/internal/service/entity"
+	"time"
+)
+
+// CouponListRequest represents the filters, order and page of a coupon
+// listing
+type CouponListRequest struct {
+	Status        string     `form:"status" binding:"omitempty,oneof=draft active paused archived"`
+	CampaignID    string     `form:"campaignId"`
+	Type          string     `form:"type" binding:"omitempty,oneof=percentage fixed_amount free_shipping buy_x_get_y"`
+	ExpiresBefore *time.Time `form:"expiresBefore" time_format:"2006-01-02T15:04:05Z07:00"`
+	CodePrefix    string     `form:"codePrefix"`
+	Sort          string     `form:"sort" binding:"omitempty,oneof=code expiresAt discount"`
+	Order         string     `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit         int        `form:"limit" binding:"gte=0,lte=200"`
+	Cursor        string     `form:"cursor"`
+}
+
+// ToEntity converts the request into a service coupon query
+func (r CouponListRequest) ToEntity() entity.CouponQuery {
+	query := entity.CouponQuery{
+		Status:     entity.CouponStatus(r.Status),
+		CampaignID: r.CampaignID,
+		Type:       entity.DiscountType(r.Type),
+		CodePrefix: r.CodePrefix,
+		Sort:       entity.CouponSort(r.Sort),
+		Descending: r.Order == "desc",
+		Limit:      r.Limit,
+	}
+	if r.ExpiresBefore != nil {
+		query.ExpiresBefore = *r.ExpiresBefore
+	}
+	return query
+}
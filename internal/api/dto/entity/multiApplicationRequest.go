@@ -0,0 +1,7 @@
+package entity
+
+// MultiApplicationRequest represents the request for applying several coupons
+type MultiApplicationRequest struct {
+	Basket Basket   `json:"basket"`
+	Codes  []string `json:"codes" binding:"required,min=1" example:"SUMMER2024,FREESHIP"`
+}
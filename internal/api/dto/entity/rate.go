@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"encoding/json"
+	"reviewsch/internal/service/entity"
+	"time"
+)
+
+// RateTable represents a new version of the exchange-rate table
+// @Description Exchange rates taking effect at validFrom, or immediately when it is omitted
+type RateTable struct {
+	ValidFrom *time.Time     `json:"validFrom,omitempty" example:"2024-06-01T00:00:00Z"`
+	Rates     []ExchangeRate `json:"rates" binding:"required,min=1,dive"`
+}
+
+// ExchangeRate represents the price of one unit of from in to
+type ExchangeRate struct {
+	From string      `json:"from" binding:"required,len=3" example:"EUR"`
+	To   string      `json:"to" binding:"required,len=3" example:"PLN"`
+	Rate json.Number `json:"rate" binding:"required" swaggertype:"number" example:"4.3125"`
+}
+
+// ToEntity converts the request into a service rate table
+func (t RateTable) ToEntity() entity.RateTable {
+	table := entity.RateTable{Rates: make([]entity.ExchangeRate, 0, len(t.Rates))}
+	if t.ValidFrom != nil {
+		table.ValidFrom = *t.ValidFrom
+	}
+	for _, rate := range t.Rates {
+		table.Rates = append(table.Rates, entity.ExchangeRate{
+			From: rate.From,
+			To:   rate.To,
+			Rate: rate.Rate.String(),
+		})
+	}
+	return table
+}
@@ -6,8 +6,12 @@ import (
 	ratelimit "github.com/JGLTechnologies/gin-rate-limit"
 	"github.com/redis/go-redis/v9"
 	"log"
+	"math"
 	"net/http"
+	"reviewsch/internal/api/middleware/trace"
+	"reviewsch/internal/api/problem"
 	"reviewsch/internal/service/entity"
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,9 +20,37 @@ import (
 
 // Service interface defines the required business operations
 type Service interface {
-	ApplyCoupon(entity.Basket, string) (*entity.Basket, error)
-	CreateCoupon(int, string, float64) error
+	ApplyCoupon(entity.Basket, string, entity.Customer) (*entity.Basket, error)
+	ApplyCoupons(entity.Basket, []string, entity.Customer) (*entity.Application, error)
+	ValidateCoupon(entity.Basket, string, entity.Customer) *entity.Eligibility
+	ApplicableCoupons(entity.Basket, entity.Customer) ([]entity.ApplicableCoupon, error)
+	RecommendCoupons(entity.Basket, []string, entity.Customer) (*entity.Recommendation, error)
+	CustomerCoupons(entity.Customer) ([]entity.AssignedCoupon, error)
+	CreateCoupon(entity.Coupon, entity.Actor) error
+	GenerateCoupons(entity.Coupon, entity.CodeSpec, entity.Actor) ([]string, error)
 	GetCoupons([]string) ([]entity.Coupon, error)
+	GetCoupon(string) (*entity.Coupon, error)
+	ListCoupons(entity.CouponQuery, string) (*entity.CouponPage, error)
+	UpdateCoupon(string, entity.Coupon, int, entity.Actor) (*entity.Coupon, error)
+	DeleteCoupon(string, int, entity.Actor) error
+	ListCouponVersions(string) ([]entity.CouponVersion, error)
+	GetCouponVersion(string, int) (*entity.CouponVersion, error)
+	GetCouponAsOf(string, time.Time) (*entity.CouponVersion, error)
+	GetCouponVersionByID(string) (*entity.CouponVersion, error)
+	DiffCouponVersions(string, int, int) (*entity.CouponDiff, error)
+	ReserveCoupon(entity.Basket, string, entity.Customer) (*entity.Basket, error)
+	CommitReservation(string, entity.Customer) error
+	ReleaseReservation(string, entity.Customer) error
+	ActivateCoupon(string, entity.Actor) (*entity.Coupon, error)
+	PauseCoupon(string, entity.Actor) (*entity.Coupon, error)
+	ResumeCoupon(string, entity.Actor) (*entity.Coupon, error)
+	ArchiveCoupon(string, entity.Actor) (*entity.Coupon, error)
+	CreateCampaign(entity.Campaign) (*entity.Campaign, error)
+	GetCampaign(string) (*entity.Campaign, error)
+	LoadRates(entity.RateTable) (*entity.RateTable, error)
+	GetRates(time.Time) (*entity.RateTable, error)
+	GetRateVersion(int) (*entity.RateTable, error)
+	ListAudit(entity.AuditQuery) ([]entity.AuditEntry, error)
 }
 
 // RateLimitConfig holds the rate limiting configuration
@@ -42,6 +74,7 @@ type Config struct {
 	AllowedOrigins []string
 	AllowedMethods []string
 	RateLimit      RateLimitConfig
+	Idempotency    IdempotencyConfig
 }
 
 // Gateway represents the API Gateway
@@ -54,6 +87,7 @@ type Gateway struct {
 	routes      []RouteDefinition
 	mu          sync.RWMutex
 	redisClient *redis.Client
+	idempotency gin.HandlerFunc
 }
 
 // RouteDefinition defines structure for route registration
@@ -68,7 +102,8 @@ type RouteDefinition struct {
 func New(cfg Config) *Gateway {
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
-	engine.Use(gin.Recovery())
+	engine.Use(trace.Middleware(), problem.Recovery())
+	engine.NoRoute(problem.NoRoute)
 
 	g := &Gateway{
 		Engine:   engine,
@@ -83,6 +118,9 @@ func New(cfg Config) *Gateway {
 		log.Println("Rate limit enabled")
 		g.setupRateLimit()
 	}
+	if cfg.Idempotency.Enabled {
+		g.setupIdempotency()
+	}
 	return g
 }
 
@@ -142,10 +180,7 @@ func (g *Gateway) setupRateLimit() {
 				c.ClientIP(),
 				time.Until(info.ResetTime),
 			)
-			c.JSON(429, gin.H{
-				"error": fmt.Sprintf("Rate limit exceeded. Try again in %v",
-					time.Until(info.ResetTime)),
-			})
+			RateLimitExceeded(c, info)
 		}
 	} else {
 		// Wrap the existing error handler to add logging
@@ -159,7 +194,20 @@ func (g *Gateway) setupRateLimit() {
 		}
 	}
 
-	// Initialize Redis client
+	g.connectRedis()
+
+	// Add rate limit middleware to the engine
+	g.UseMiddleware(g.createRateLimitMiddleware())
+	log.Println("Rate limit middleware configured")
+}
+
+// connectRedis opens the Redis connection shared by the rate limiter and
+// the idempotency store, once
+func (g *Gateway) connectRedis() *redis.Client {
+	if g.redisClient != nil {
+		return g.redisClient
+	}
+
 	g.redisClient = redis.NewClient(&redis.Options{
 		Addr:     g.config.RateLimit.RedisAddr,
 		Password: g.config.RateLimit.RedisPass,
@@ -172,10 +220,39 @@ func (g *Gateway) setupRateLimit() {
 	if err := g.redisClient.Ping(ctx).Err(); err != nil {
 		log.Printf("Warning: Redis connection failed: %v", err)
 	}
+	return g.redisClient
+}
 
-	// Add rate limit middleware to the engine
-	g.UseMiddleware(g.createRateLimitMiddleware())
-	log.Println("Rate limit middleware configured")
+func (g *Gateway) setupIdempotency() {
+	var store IdempotencyStore
+	switch g.config.Idempotency.Store {
+	case "redis":
+		store = NewRedisIdempotencyStore(g.connectRedis())
+	case "memory":
+		store = NewMemoryIdempotencyStore()
+	default:
+		panic(g.config.Idempotency.Validate())
+	}
+	g.idempotency = Idempotency(store, g.config.Idempotency.TTL)
+	log.Printf("Idempotency keys enabled (%s store, TTL %v)", g.config.Idempotency.Store, g.config.Idempotency.TTL)
+}
+
+// Idempotency returns the idempotency key middleware for routes with side
+// effects, or a pass-through when idempotency keys are disabled
+func (g *Gateway) Idempotency() gin.HandlerFunc {
+	if g.idempotency == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return g.idempotency
+}
+
+// RateLimitExceeded rejects a request over the rate limit with a problem
+// response and a Retry-After header
+func RateLimitExceeded(c *gin.Context, info ratelimit.Info) {
+	retryAfter := time.Until(info.ResetTime)
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	problem.Write(c, http.StatusTooManyRequests, problem.ReasonRateLimited,
+		fmt.Sprintf("Rate limit exceeded. Try again in %v", retryAfter))
 }
 
 // RegisterService adds a service to the gateway
@@ -247,7 +324,8 @@ func CORSMiddleware(config Config) gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Methods",
 			fmt.Sprintf("%v", config.AllowedMethods))
 		c.Writer.Header().Set("Access-Control-Allow-Headers",
-			"Content-Type, Authorization")
+			"Content-Type, Authorization, If-Match, "+IdempotencyHeader+", "+trace.Header)
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, "+ReplayedHeader+", "+trace.Header)
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reviewsch/internal/api/middleware/trace"
+	"reviewsch/internal/api/problem"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// IdempotencyHeader names the client-chosen key of a retried request
+	IdempotencyHeader = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed for a retried request
+	ReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKey is the longest key accepted
+	maxIdempotencyKey = 255
+)
+
+// IdempotencyConfig holds the idempotency key configuration. Store is
+// "memory" or "redis"; Redis uses the rate limit connection settings.
+type IdempotencyConfig struct {
+	Enabled bool
+	Store   string
+	TTL     time.Duration
+}
+
+// Validate checks that Store names a known store
+func (c IdempotencyConfig) Validate() error {
+	switch c.Store {
+	case "memory", "redis":
+		return nil
+	}
+	return fmt.Errorf("unknown idempotency store %q, want \"memory\" or \"redis\"", c.Store)
+}
+
+// StoredResponse is the first response to a request with an idempotency
+// key. Fingerprint identifies the request it answered; a response that is
+// not Done is still being produced.
+type StoredResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Done        bool        `json:"done"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// IdempotencyStore keeps the first response per idempotency key. Begin
+// claims a free key with a pending response and returns nil, or returns
+// what is stored for a key already in use. Complete stores the response of
+// the claimed key and Release frees it again. Keys expire after the TTL.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*StoredResponse, error)
+	Complete(ctx context.Context, key string, response StoredResponse, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency replays the stored response when a request is retried with
+// the same Idempotency-Key by the same caller, so retries do not repeat
+// side effects. A key reused with another request is rejected, as is a
+// retry while the first request is still running. Server errors and
+// panics are not stored, so the request can be retried. Requests without the header pass
+// through. It must run after auth.AdminAuth, which identifies the caller.
+func Idempotency(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			problem.Abort(c, http.StatusBadRequest, problem.ReasonInvalidRequest,
+				fmt.Sprintf("%s longer than %d characters", IdempotencyHeader, maxIdempotencyKey))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			problem.Abort(c, http.StatusBadRequest, problem.ReasonInvalidRequest, "reading request body: "+err.Error())
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scoped := fmt.Sprintf("idempotency:%s:%s", c.GetString("userID"), key)
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		stored, err := store.Begin(ctx, scoped, fingerprint, ttl)
+		if err != nil {
+			log.Printf("idempotency key %q [%s]: %v", key, trace.ID(c), err)
+			problem.Abort(c, http.StatusInternalServerError, problem.ReasonInternalError, "internal error")
+			return
+		}
+		switch {
+		case stored == nil:
+		case stored.Fingerprint != fingerprint:
+			problem.Abort(c, http.StatusUnprocessableEntity, problem.ReasonIdempotencyKeyReused,
+				fmt.Sprintf("%s %q was used for a different request", IdempotencyHeader, key))
+			return
+		case !stored.Done:
+			problem.Abort(c, http.StatusConflict, problem.ReasonIdempotencyInProgress,
+				fmt.Sprintf("a request with %s %q is still being processed", IdempotencyHeader, key))
+			return
+		default:
+			replay(c, stored)
+			return
+		}
+
+		// Free the key unless the response was stored, also when a
+		// handler panics, so it is not left pending until it expires
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := store.Release(ctx, scoped); err != nil {
+				log.Printf("idempotency key %q [%s]: %v", key, trace.ID(c), err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		header := recorder.Header().Clone()
+		header.Del(trace.Header)
+		err = store.Complete(ctx, scoped, StoredResponse{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Status:      status,
+			Header:      header,
+			Body:        recorder.body.Bytes(),
+		}, ttl)
+		if err != nil {
+			log.Printf("idempotency key %q [%s]: %v", key, trace.ID(c), err)
+			return
+		}
+		completed = true
+	}
+}
+
+func requestFingerprint(method, path string, body []byte) string {
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s %s\n", method, path)
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func replay(c *gin.Context, stored *StoredResponse) {
+	for name, values := range stored.Header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Header(ReplayedHeader, "true")
+	c.Status(stored.Status)
+	if _, err := c.Writer.Write(stored.Body); err != nil {
+		log.Printf("replaying response [%s]: %v", trace.ID(c), err)
+	}
+	c.Abort()
+}
+
+// responseRecorder keeps a copy of the response body while writing it
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// MemoryIdempotencyStore keeps idempotency keys in memory, for a single
+// gateway instance
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	expiry  expiryHeap
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	response  StoredResponse
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Begin claims the key unless an unexpired response is stored for it.
+// Expired keys are dropped along the way.
+func (s *MemoryIdempotencyStore) Begin(_ context.Context, key, fingerprint string, ttl time.Duration) (*StoredResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+
+	if entry, ok := s.entries[key]; ok {
+		response := entry.response
+		return &response, nil
+	}
+	s.store(key, memoryEntry{
+		response:  StoredResponse{Fingerprint: fingerprint},
+		expiresAt: now.Add(ttl),
+	})
+	return nil, nil
+}
+
+// Complete stores the response of a claimed key
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, response StoredResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(key, memoryEntry{response: response, expiresAt: s.now().Add(ttl)})
+	return nil
+}
+
+// store saves the entry and schedules its expiry
+func (s *MemoryIdempotencyStore) store(key string, entry memoryEntry) {
+	s.entries[key] = entry
+	heap.Push(&s.expiry, expiryItem{key: key, expiresAt: entry.expiresAt})
+}
+
+// expire drops the keys that expired by now. Only the expired items are
+// popped off the heap; items left by a key that was completed or released
+// since no longer match its entry and are skipped.
+func (s *MemoryIdempotencyStore) expire(now time.Time) {
+	for len(s.expiry) > 0 && !now.Before(s.expiry[0].expiresAt) {
+		item := heap.Pop(&s.expiry).(expiryItem)
+		if entry, ok := s.entries[item.key]; ok && entry.expiresAt.Equal(item.expiresAt) {
+			delete(s.entries, item.key)
+		}
+	}
+}
+
+// Release frees a claimed key
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// expiryItem schedules the expiry of a key in the memory store
+type expiryItem struct {
+	key       string
+	expiresAt time.Time
+}
+
+// expiryHeap orders scheduled expiries, the earliest first
+type expiryHeap []expiryItem
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *expiryHeap) Push(x any)        { *h = append(*h, x.(expiryItem)) }
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// RedisIdempotencyStore keeps idempotency keys in Redis, so retries are
+// recognized by every gateway instance
+type RedisIdempotencyStore struct {
+	client *redis.Client
+}
+
+// NewRedisIdempotencyStore creates a store on the Redis connection
+func NewRedisIdempotencyStore(client *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client}
+}
+
+// Begin claims the key with SET NX, so only one request can claim it
+func (s *RedisIdempotencyStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*StoredResponse, error) {
+	pending, err := json.Marshal(StoredResponse{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := s.client.SetNX(ctx, key, pending, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Released or expired in between; treat it as still in use so
+		// the client retries
+		return &StoredResponse{Fingerprint: fingerprint}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var stored StoredResponse
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// Complete stores the response of a claimed key
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, response StoredResponse, ttl time.Duration) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, data, ttl).Err()
+}
+
+// Release frees a claimed key
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"reviewsch/internal/api/problem"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdempotentRouter(store IdempotencyStore, calls *int, status int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-User"))
+	})
+	router.POST("/coupons/create", Idempotency(store, time.Hour), func(c *gin.Context) {
+		*calls++
+		c.Header("ETag", `"1"`)
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func post(router *gin.Engine, key, user, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/coupons/create", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyHeader, key)
+	}
+	req.Header.Set("X-User", user)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	router := newIdempotentRouter(NewMemoryIdempotencyStore(), &calls, http.StatusOK)
+
+	first := post(router, "k1", "u1", `{"code":"A"}`)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.JSONEq(t, `{"call":1}`, first.Body.String())
+
+	retry := post(router, "k1", "u1", `{"code":"A"}`)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.JSONEq(t, `{"call":1}`, retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(ReplayedHeader))
+	assert.Equal(t, `"1"`, retry.Header().Get("ETag"))
+
+	reused := post(router, "k1", "u1", `{"code":"B"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	assert.Contains(t, reused.Body.String(), problem.ReasonIdempotencyKeyReused)
+
+	otherCaller := post(router, "k1", "u2", `{"code":"A"}`)
+	assert.JSONEq(t, `{"call":2}`, otherCaller.Body.String())
+
+	withoutKey := post(router, "", "u1", `{"code":"A"}`)
+	assert.JSONEq(t, `{"call":3}`, withoutKey.Body.String())
+
+	tooLong := post(router, strings.Repeat("k", maxIdempotencyKey+1), "u1", `{}`)
+	assert.Equal(t, http.StatusBadRequest, tooLong.Code)
+	assert.Equal(t, 3, calls)
+}
+
+func TestIdempotency_ServerErrorNotStored(t *testing.T) {
+	calls := 0
+	router := newIdempotentRouter(NewMemoryIdempotencyStore(), &calls, http.StatusInternalServerError)
+
+	post(router, "k1", "u1", `{}`)
+	retry := post(router, "k1", "u1", `{}`)
+
+	assert.Empty(t, retry.Header().Get(ReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryIdempotencyStore()
+	calls := 0
+	router := gin.New()
+	router.Use(problem.Recovery())
+	router.POST("/coupons/create", Idempotency(store, time.Hour), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusOK, gin.H{"call": calls})
+	})
+
+	first := post(router, "k1", "u1", `{}`)
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+	assert.Empty(t, store.entries)
+
+	retry := post(router, "k1", "u1", `{}`)
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.JSONEq(t, `{"call":2}`, retry.Body.String())
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	_, err := store.Begin(context.Background(), "idempotency:u1:k1", requestFingerprint("POST", "/coupons/create", []byte(`{}`)), time.Hour)
+	assert.NoError(t, err)
+
+	calls := 0
+	router := newIdempotentRouter(store, &calls, http.StatusOK)
+	w := post(router, "k1", "u1", `{}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), problem.ReasonIdempotencyInProgress)
+	assert.Equal(t, 0, calls)
+}
+
+func TestMemoryIdempotencyStore_Expiry(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	stored, err := store.Begin(ctx, "k", "f1", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	assert.NoError(t, store.Complete(ctx, "k", StoredResponse{Fingerprint: "f1", Done: true, Status: 201}, time.Minute))
+
+	stored, err = store.Begin(ctx, "k", "f1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 201, stored.Status)
+
+	now = now.Add(time.Minute)
+	stored, err = store.Begin(ctx, "k", "f2", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestMemoryIdempotencyStore_ExpiryKeepsRenewedKeys(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := store.Begin(ctx, "k", "f1", time.Minute)
+	assert.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, store.Complete(ctx, "k", StoredResponse{Fingerprint: "f1", Done: true, Status: 201}, time.Minute))
+
+	// The claim would have expired by now, the completed response has not
+	now = now.Add(45 * time.Second)
+	stored, err := store.Begin(ctx, "other", "f2", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+	stored, err = store.Begin(ctx, "k", "f1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 201, stored.Status)
+
+	assert.NoError(t, store.Release(ctx, "other"))
+	now = now.Add(time.Minute)
+	_, err = store.Begin(ctx, "next", "f3", time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, store.entries, 1)
+	assert.Len(t, store.expiry, 1)
+}
+
+func TestIdempotencyConfig_Validate(t *testing.T) {
+	assert.NoError(t, IdempotencyConfig{Store: "memory"}.Validate())
+	assert.NoError(t, IdempotencyConfig{Store: "redis"}.Validate())
+	assert.ErrorContains(t, IdempotencyConfig{Store: "reddis"}.Validate(), `unknown idempotency store "reddis"`)
+}
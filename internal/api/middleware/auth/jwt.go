@@ -3,9 +3,12 @@ package auth
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"reviewsch/internal/api/problem"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -14,6 +17,9 @@ var (
 	secretKey = []byte("5chw4rz!T45k")
 )
 
+// RoleAdmin is the token role of operators who manage coupons
+const RoleAdmin = "admin"
+
 // Claims represents JWT claims
 type Claims struct {
 	UserID string `json:"user_id"`
@@ -47,16 +53,14 @@ func AdminAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "missing authorization header"})
-			c.Abort()
+			problem.Abort(c, http.StatusUnauthorized, problem.ReasonUnauthorized, "missing authorization header")
 			return
 		}
 
 		// Bearer token format
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token format"})
-			c.Abort()
+			problem.Abort(c, http.StatusUnauthorized, problem.ReasonUnauthorized, "invalid token format")
 			return
 		}
 
@@ -71,8 +75,7 @@ func AdminAuth() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
+			problem.Abort(c, http.StatusUnauthorized, problem.ReasonUnauthorized, "invalid token")
 			return
 		}
 
@@ -82,3 +85,17 @@ func AdminAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireRole rejects callers whose token role is not one of the roles. It
+// must run after AdminAuth, which sets the role from the token.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if !slices.Contains(roles, role) {
+			problem.Abort(c, http.StatusForbidden, problem.ReasonForbidden,
+				fmt.Sprintf("role %q may not access this resource", role))
+			return
+		}
+		c.Next()
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"reviewsch/internal/api/problem"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -158,6 +160,11 @@ func TestAdminAuth(t *testing.T) {
 			// Assert response
 			assert.Equal(t, tt.expectedCode, w.Code)
 
+			if tt.expectedCode == http.StatusUnauthorized {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), `"reason":"unauthorized"`)
+			}
+
 			if tt.expectedCode == http.StatusOK {
 				assert.Equal(t, tt.expectedUserID, capturedUserID)
 				assert.Equal(t, tt.expectedRole, capturedRole)
@@ -165,3 +172,38 @@ func TestAdminAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		role         string
+		expectedCode int
+	}{
+		{name: "admin", role: RoleAdmin, expectedCode: http.StatusOK},
+		{name: "customer", role: "customer", expectedCode: http.StatusForbidden},
+		{name: "no role", role: "", expectedCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(AdminAuth(), RequireRole(RoleAdmin))
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			token, _ := GenerateToken("123", tt.role)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusForbidden {
+				assert.Contains(t, w.Body.String(), `"reason":"forbidden"`)
+			}
+		})
+	}
+}
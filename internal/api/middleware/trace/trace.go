@@ -0,0 +1,61 @@
+package trace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header carries the trace id of a request and its response
+const Header = "X-Request-ID"
+
+const contextKey = "traceID"
+
+var (
+	// validID limits the trace ids accepted from clients, so they can be
+	// logged and echoed safely
+	validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+	// traceParent is a W3C traceparent header, e.g. from a proxy
+	traceParent = regexp.MustCompile(`^[0-9a-f]{2}-([0-9a-f]{32})-[0-9a-f]{16}-[0-9a-f]{2}$`)
+)
+
+// Middleware gives every request a trace id. It keeps the id sent by the
+// client in X-Request-ID or traceparent and generates one otherwise. The
+// id is returned in the X-Request-ID response header.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := fromRequest(c)
+		if id == "" {
+			id = newID()
+		}
+		c.Set(contextKey, id)
+		c.Header(Header, id)
+		c.Next()
+	}
+}
+
+// ID returns the trace id of the request, or "" outside of Middleware
+func ID(c *gin.Context) string {
+	return c.GetString(contextKey)
+}
+
+func fromRequest(c *gin.Context) string {
+	if id := strings.TrimSpace(c.GetHeader(Header)); validID.MatchString(id) {
+		return id
+	}
+	if match := traceParent.FindStringSubmatch(c.GetHeader("traceparent")); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
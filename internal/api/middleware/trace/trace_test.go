@@ -0,0 +1,53 @@
+package trace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		expect  string
+	}{
+		{name: "request id kept", headers: map[string]string{Header: "req-42"}, expect: "req-42"},
+		{name: "traceparent", headers: map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, expect: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{name: "request id wins", headers: map[string]string{Header: "req-42", "traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, expect: "req-42"},
+		{name: "unsafe id replaced", headers: map[string]string{Header: "bad id\n"}},
+		{name: "generated", headers: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Middleware())
+
+			var captured string
+			router.GET("/test", func(c *gin.Context) {
+				captured = ID(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			router.ServeHTTP(w, req)
+
+			if tt.expect != "" {
+				assert.Equal(t, tt.expect, captured)
+			} else {
+				assert.Regexp(t, `^[0-9a-f]{32}$`, captured)
+			}
+			assert.Equal(t, captured, w.Header().Get(Header))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package problem
+
+import (
+	"net/http"
+
+	"reviewsch/internal/api/middleware/trace"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of problem details, RFC 7807
+const ContentType = "application/problem+json"
+
+// TypePrefix is followed by the reason code to form the problem type URI
+const TypePrefix = "urn:coupon-service:problem:"
+
+// Reason codes shared by the gateway, the middleware and the handlers
+const (
+	ReasonInvalidRequest = "invalid_request"
+	ReasonInternalError  = "internal_error"
+	ReasonUnauthorized   = "unauthorized"
+	ReasonForbidden      = "forbidden"
+	ReasonRateLimited    = "rate_limited"
+	ReasonRouteNotFound  = "route_not_found"
+
+	ReasonIdempotencyKeyReused  = "idempotency_key_reused"
+	ReasonIdempotencyInProgress = "idempotency_in_progress"
+)
+
+// Problem is the body of every error response
+type Problem struct {
+	Type     string `json:"type" example:"urn:coupon-service:problem:coupon_expired"`
+	Title    string `json:"title" example:"Unprocessable Entity"`
+	Status   int    `json:"status" example:"422"`
+	Detail   string `json:"detail,omitempty" example:"coupon expired: expired at 2024-09-01T00:00:00Z"`
+	Instance string `json:"instance" example:"/api/v1/coupons/apply"`
+	Reason   string `json:"reason" example:"coupon_expired"`
+	TraceID  string `json:"traceId,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+}
+
+// New describes a failed request
+func New(c *gin.Context, status int, reason, detail string) Problem {
+	return Problem{
+		Type:     TypePrefix + reason,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Reason:   reason,
+		TraceID:  trace.ID(c),
+	}
+}
+
+// Write writes a problem response
+func Write(c *gin.Context, status int, reason, detail string) {
+	c.Header("Content-Type", ContentType)
+	c.JSON(status, New(c, status, reason, detail))
+}
+
+// Abort writes a problem response and stops the remaining handlers
+func Abort(c *gin.Context, status int, reason, detail string) {
+	Write(c, status, reason, detail)
+	c.Abort()
+}
+
+// Recovery turns panics into internal error responses. The panic and its
+// stack are logged by gin.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, _ any) {
+		Abort(c, http.StatusInternalServerError, ReasonInternalError, "internal error")
+	})
+}
+
+// NoRoute answers requests for unknown paths
+func NoRoute(c *gin.Context) {
+	Write(c, http.StatusNotFound, ReasonRouteNotFound, "no route for "+c.Request.Method+" "+c.Request.URL.Path)
+}
@@ -0,0 +1,77 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"reviewsch/internal/api/middleware/trace"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(t *testing.T, router *gin.Engine, path string) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	req.Header.Set(trace.Header, "req-1")
+	router.ServeHTTP(w, req)
+
+	var body Problem
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	return w, body
+}
+
+func TestWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(trace.Middleware())
+	router.GET("/coupons/:code", func(c *gin.Context) {
+		Write(c, http.StatusNotFound, "coupon_not_found", "coupon not found")
+	})
+
+	w, body := serve(t, router, "/coupons/X")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, Problem{
+		Type:     TypePrefix + "coupon_not_found",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "coupon not found",
+		Instance: "/coupons/X",
+		Reason:   "coupon_not_found",
+		TraceID:  "req-1",
+	}, body)
+}
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(trace.Middleware(), Recovery())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w, body := serve(t, router, "/panic")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, ReasonInternalError, body.Reason)
+	assert.Equal(t, "internal error", body.Detail)
+	assert.Equal(t, "req-1", body.TraceID)
+}
+
+func TestNoRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.NoRoute(NoRoute)
+
+	w, body := serve(t, router, "/missing")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ReasonRouteNotFound, body.Reason)
+	assert.Empty(t, body.TraceID)
+}
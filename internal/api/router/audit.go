@@ -0,0 +1,56 @@
+package router
+
+import (
+	"net/http"
+	. "reviewsch/internal/api/dto/entity"
+	"reviewsch/internal/api/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler handles audit log queries
+type AuditHandler struct {
+	svc handler.Service
+}
+
+// NewAuditHandler creates a new AuditHandler instance
+func NewAuditHandler(svc handler.Service) *AuditHandler {
+	return &AuditHandler{
+		svc: svc,
+	}
+}
+
+// List godoc
+// @Summary List audit entries
+// @Description List the recorded coupon mutations matching the filters, newest first
+// @Tags Audit
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code query string false "Coupon code"
+// @Param actor query string false "ID of the user who made the change"
+// @Param action query string false "Kind of change" Enums(create, update, status, delete)
+// @Param from query string false "Only changes at or after this time (RFC 3339)"
+// @Param to query string false "Only changes before this time (RFC 3339)"
+// @Param limit query int false "Number of entries, 100 by default and at most 1000"
+// @Success 200 {array} reviewsch_internal_service_entity.AuditEntry
+// @Router /v1/audit [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *AuditHandler) List(c *gin.Context) {
+	apiReq := AuditListRequest{}
+	if err := c.ShouldBindQuery(&apiReq); err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	entries, err := h.svc.ListAudit(apiReq.ToEntity())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
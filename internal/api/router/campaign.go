@@ -0,0 +1,83 @@
+package router
+
+import (
+	"net/http"
+	. "reviewsch/internal/api/dto/entity"
+	"reviewsch/internal/api/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CampaignHandler handles campaign-related operations
+type CampaignHandler struct {
+	svc handler.Service
+}
+
+// NewCampaignHandler creates a new CampaignHandler instance
+func NewCampaignHandler(svc handler.Service) *CampaignHandler {
+	return &CampaignHandler{
+		svc: svc,
+	}
+}
+
+// Create godoc
+// @Summary Create a campaign
+// @Description Create a campaign with a discount and redemption budget; its coupons pause once a budget is used up
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param campaign body Campaign true "Campaign definition"
+// @Success 201 {object} reviewsch_internal_service_entity.Campaign
+// @Router /v1/campaigns [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CampaignHandler) Create(c *gin.Context) {
+	apiReq := Campaign{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	campaignReq, err := apiReq.ToEntity()
+	if err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	campaign, err := h.svc.CreateCampaign(campaignReq)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, campaign)
+}
+
+// Get godoc
+// @Summary Get a campaign
+// @Description Retrieve a campaign with its current spend
+// @Tags Campaigns
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} reviewsch_internal_service_entity.Campaign
+// @Router /v1/campaigns/{id} [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CampaignHandler) Get(c *gin.Context) {
+	campaign, err := h.svc.GetCampaign(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, campaign)
+}
@@ -1,10 +1,16 @@
 package router
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
 	"net/http"
 	. "reviewsch/internal/api/dto/entity"
 	"reviewsch/internal/api/handler"
+	"reviewsch/internal/api/middleware/trace"
+	"reviewsch/internal/api/problem"
+	"reviewsch/internal/service/entity"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,54 +31,253 @@ func NewCouponHandler(svc handler.Service) *CouponHandler {
 // @Summary Apply a coupon to a basket
 // @Description Apply a coupon to a basket
 // @Tags Coupons
+// @Accept json
 // @Produce json
-// @Success 200 {object} entity.Basket
-// @Router /v1/coupons/apply [get]
-// @Security Bearer
 // @Param Authorization header string true "Bearer JWT token"
+// @Param Idempotency-Key header string false "Client-chosen key; retries with the same key and body replay the first response"
+// @Param request body ApplicationRequest true "Basket and coupon code"
+// @Success 200 {object} reviewsch_internal_service_entity.Basket
+// @Router /v1/coupons/apply [post]
+// @Security Bearer
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Personal coupon of another customer"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 422 {object} ErrorResponse "Coupon cannot be applied"
+// @Failure 500 {object} ErrorResponse "Internal error"
 func (h *CouponHandler) Apply(c *gin.Context) {
 	apiReq := ApplicationRequest{}
 	if err := c.ShouldBindJSON(&apiReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	basketReq, err := apiReq.Basket.ToEntity()
+	if err != nil {
+		invalidRequest(c, err.Error())
 		return
 	}
 
-	basket, err := h.svc.ApplyCoupon(apiReq.Basket, apiReq.Code)
+	basket, err := h.svc.ApplyCoupon(basketReq, apiReq.Code, customer(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, basket)
 }
 
+// Validate godoc
+// @Summary Check whether a coupon applies to a basket
+// @Description Run the apply checks without redeeming the coupon and return each check with the values it compared; the checks after the first one that failed are skipped
+// @Tags Coupons
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param request body ApplicationRequest true "Basket and coupon code"
+// @Success 200 {object} reviewsch_internal_service_entity.Eligibility
+// @Router /v1/coupons/validate [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Validate(c *gin.Context) {
+	apiReq := ApplicationRequest{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	basketReq, err := apiReq.Basket.ToEntity()
+	if err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	result := h.svc.ValidateCoupon(basketReq, apiReq.Code, customer(c))
+	if result.Err != nil {
+		status, reason := errorStatus(result.Err)
+		if status == http.StatusInternalServerError {
+			respondError(c, result.Err)
+			return
+		}
+		result.Reason = reason
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Applicable godoc
+// @Summary List the coupons a basket qualifies for
+// @Description Find every active coupon the basket and the calling customer qualify for, public or assigned to the customer, with the discount each would grant on its own, largest first
+// @Tags Coupons
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param request body ApplicableRequest true "Basket"
+// @Success 200 {array} reviewsch_internal_service_entity.ApplicableCoupon
+// @Router /v1/coupons/applicable [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Applicable(c *gin.Context) {
+	apiReq := ApplicableRequest{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	basketReq, err := apiReq.Basket.ToEntity()
+	if err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	coupons, err := h.svc.ApplicableCoupons(basketReq, customer(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupons)
+}
+
+// Mine godoc
+// @Summary List the calling customer's personal coupons
+// @Description List the coupons assigned to the customer in the JWT that are active, unused and unexpired, those expiring first at the top
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {array} reviewsch_internal_service_entity.AssignedCoupon
+// @Router /v1/coupons/mine [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Mine(c *gin.Context) {
+	coupons, err := h.svc.CustomerCoupons(customer(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupons)
+}
+
+// ApplyMultiple godoc
+// @Summary Apply several coupons to a basket
+// @Description Apply the combination of codes that gives the largest valid discount and explain why any code was dropped
+// @Tags Coupons
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param Idempotency-Key header string false "Client-chosen key; retries with the same key and body replay the first response"
+// @Param request body MultiApplicationRequest true "Basket and coupon codes"
+// @Success 200 {object} reviewsch_internal_service_entity.Application
+// @Router /v1/coupons/apply-multiple [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 422 {object} ErrorResponse "Unprocessable request"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) ApplyMultiple(c *gin.Context) {
+	apiReq := MultiApplicationRequest{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	basket, err := apiReq.Basket.ToEntity()
+	if err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	application, err := h.svc.ApplyCoupons(basket, apiReq.Codes, customer(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	dropReasons(application.Dropped)
+
+	c.JSON(http.StatusOK, application)
+}
+
+// Recommend godoc
+// @Summary Recommend the best coupons for a basket
+// @Description Pick the code or stackable set of codes giving the largest discount among the codes the customer holds, without applying them, and rank the other valid combinations by savings
+// @Tags Coupons
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param request body MultiApplicationRequest true "Basket and candidate coupon codes"
+// @Success 200 {object} reviewsch_internal_service_entity.Recommendation
+// @Router /v1/coupons/recommend [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Recommend(c *gin.Context) {
+	apiReq := MultiApplicationRequest{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	basketReq, err := apiReq.Basket.ToEntity()
+	if err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	recommendation, err := h.svc.RecommendCoupons(basketReq, apiReq.Codes, customer(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	dropReasons(recommendation.Dropped)
+
+	c.JSON(http.StatusOK, recommendation)
+}
+
 // Create godoc
 // @Summary Create a new coupon
-// @Description Create a new coupon
+// @Description Create a new coupon, optionally limited to a validity window
 // @Tags Coupons
+// @Accept json
 // @Produce json
 // @Success 200 {object} SuccessResponse
 // @Router /v1/coupons/create [post]
 // @Security Bearer
 // @Param Authorization header string true "Bearer JWT token"
+// @Param Idempotency-Key header string false "Client-chosen key; retries with the same key and body replay the first response"
+// @Param coupon body Coupon true "Coupon definition"
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Coupon code already exists"
+// @Failure 422 {object} ErrorResponse "Unprocessable request"
+// @Failure 500 {object} ErrorResponse "Internal error"
 func (h *CouponHandler) Create(c *gin.Context) {
 	apiReq := Coupon{}
 	fmt.Println("lol")
 	if err := c.ShouldBindJSON(&apiReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request format: " + err.Error(),
-		})
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	coupon, err := apiReq.ToEntity()
+	if err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
 		return
 	}
 
-	if err := h.svc.CreateCoupon(apiReq.Discount, apiReq.Code, apiReq.MinBasketValue); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+	if err := h.svc.CreateCoupon(coupon, actor(c)); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -81,39 +286,491 @@ func (h *CouponHandler) Create(c *gin.Context) {
 	})
 }
 
+// Generate godoc
+// @Summary Generate unique single-use coupons
+// @Description Mint unique single-use codes from a template and download them as CSV
+// @Tags Coupons
+// @Accept json
+// @Produce text/csv
+// @Param Authorization header string true "Bearer JWT token"
+// @Param Idempotency-Key header string false "Client-chosen key; retries with the same key and body replay the first response"
+// @Param request body GenerateRequest true "Code format and coupon template"
+// @Success 200 {file} file "CSV file with one generated code per line"
+// @Router /v1/coupons/generate [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 422 {object} ErrorResponse "Unprocessable request"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Generate(c *gin.Context) {
+	apiReq := GenerateRequest{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	template, err := apiReq.Template.ToEntity()
+	if err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	codes, err := h.svc.GenerateCoupons(template, apiReq.CodeSpec(), actor(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	records := make([][]string, 0, len(codes)+1)
+	records = append(records, []string{"code"})
+	for _, code := range codes {
+		records = append(records, []string{code})
+	}
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="coupons.csv"`)
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
+
 // Get godoc
 // @Summary Get coupons by codes
 // @Description Retrieve multiple coupons by their codes
 // @Tags Coupons
 // @Accept json
 // @Produce json
-// @Success 200 {objects} entity.Coupon
-// @Router /v1/coupons [get]
+// @Success 200 {array} reviewsch_internal_service_entity.Coupon
+// @Router /v1/coupons/ [get]
 // @Security Bearer
 // @Param Authorization header string true "Bearer JWT token"
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
 func (h *CouponHandler) Get(c *gin.Context) {
 	apiReq := CouponRequest{}
 	if err := c.ShouldBindJSON(&apiReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		invalidRequest(c, err.Error())
 		return
 	}
 
 	coupons, err := h.svc.GetCoupons(apiReq.Codes)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, coupons)
 }
 
-// ErrorResponse Define response structures for Swagger
-type ErrorResponse struct {
-	Error string `json:"error"`
+// List godoc
+// @Summary List coupons
+// @Description List coupons matching the filters, one page at a time; pass nextCursor as cursor to get the following page
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param status query string false "Lifecycle status" Enums(draft, active, paused, archived)
+// @Param campaignId query string false "Campaign ID"
+// @Param type query string false "Discount type" Enums(percentage, fixed_amount, free_shipping, buy_x_get_y)
+// @Param expiresBefore query string false "Only coupons expiring before this time (RFC 3339)"
+// @Param codePrefix query string false "Code prefix"
+// @Param sort query string false "Sort field" Enums(code, expiresAt, discount)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Param limit query int false "Page size, at most 200"
+// @Param cursor query string false "Cursor of the page to get"
+// @Success 200 {object} reviewsch_internal_service_entity.CouponPage
+// @Router /v1/coupons [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) List(c *gin.Context) {
+	apiReq := CouponListRequest{}
+	if err := c.ShouldBindQuery(&apiReq); err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	page, err := h.svc.ListCoupons(apiReq.ToEntity(), apiReq.Cursor)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetOne godoc
+// @Summary Get a coupon
+// @Description Retrieve a coupon; the ETag header carries its version for updates
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Success 200 {object} reviewsch_internal_service_entity.Coupon
+// @Header 200 {string} ETag "Coupon version"
+// @Router /v1/coupons/{code} [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) GetOne(c *gin.Context) {
+	coupon, err := h.svc.GetCoupon(c.Param("code"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(coupon.Version))
+	c.JSON(http.StatusOK, coupon)
+}
+
+// Replace godoc
+// @Summary Replace the terms of a coupon
+// @Description Replace every term of a coupon; the change is based on the version in If-Match or in the body
+// @Tags Coupons
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param If-Match header string false "ETag of the coupon version being replaced"
+// @Param code path string true "Coupon code"
+// @Param coupon body CouponUpdate true "New coupon terms"
+// @Success 200 {object} reviewsch_internal_service_entity.Coupon
+// @Header 200 {string} ETag "New coupon version"
+// @Router /v1/coupons/{code} [put]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Version in the body is not current"
+// @Failure 412 {object} ErrorResponse "If-Match version is not current"
+// @Failure 428 {object} ErrorResponse "No version given"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Replace(c *gin.Context) {
+	apiReq := CouponUpdate{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	h.update(c, apiReq)
+}
+
+// Modify godoc
+// @Summary Change some terms of a coupon
+// @Description Change the terms present in the body and keep the others; the change is based on the version in If-Match or in the body
+// @Tags Coupons
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param If-Match header string false "ETag of the coupon version being changed"
+// @Param code path string true "Coupon code"
+// @Param coupon body CouponUpdate true "Changed coupon terms"
+// @Success 200 {object} reviewsch_internal_service_entity.Coupon
+// @Header 200 {string} ETag "New coupon version"
+// @Router /v1/coupons/{code} [patch]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Version in the body is not current"
+// @Failure 412 {object} ErrorResponse "If-Match version is not current"
+// @Failure 428 {object} ErrorResponse "No version given"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Modify(c *gin.Context) {
+	current, err := h.svc.GetCoupon(c.Param("code"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Fields missing from the body keep their current value
+	apiReq := CouponUpdate{CouponTerms: NewCouponTerms(*current)}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	h.update(c, apiReq)
 }
 
+// Delete godoc
+// @Summary Delete a coupon
+// @Description Remove a coupon; its redemptions are kept. The deletion is based on the version in If-Match or in the query
+// @Tags Coupons
+// @Param Authorization header string true "Bearer JWT token"
+// @Param If-Match header string false "ETag of the coupon version being deleted"
+// @Param code path string true "Coupon code"
+// @Param version query int false "Coupon version being deleted"
+// @Success 204
+// @Router /v1/coupons/{code} [delete]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Version in the query is not current"
+// @Failure 412 {object} ErrorResponse "If-Match version is not current"
+// @Failure 428 {object} ErrorResponse "No version given"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Delete(c *gin.Context) {
+	requested, _ := strconv.Atoi(c.Query("version"))
+	version, header, err := expectedVersion(c, requested)
+	if err != nil {
+		versionRequired(c, err)
+		return
+	}
+
+	if err := h.svc.DeleteCoupon(c.Param("code"), version, actor(c)); err != nil {
+		versionError(c, err, header)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// update stores the new terms of the coupon in the path
+func (h *CouponHandler) update(c *gin.Context, apiReq CouponUpdate) {
+	version, header, err := expectedVersion(c, apiReq.Version)
+	if err != nil {
+		versionRequired(c, err)
+		return
+	}
+
+	terms, err := apiReq.CouponTerms.ToEntity()
+	if err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	coupon, err := h.svc.UpdateCoupon(c.Param("code"), terms, version, actor(c))
+	if err != nil {
+		versionError(c, err, header)
+		return
+	}
+
+	c.Header("ETag", etag(coupon.Version))
+	c.JSON(http.StatusOK, coupon)
+}
+
+// Reserve godoc
+// @Summary Reserve a coupon for a basket
+// @Description Apply a coupon to a basket, charge its campaign and hold the redemption until the order is committed or released
+// @Tags Coupons
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param Idempotency-Key header string false "Client-chosen key; retries with the same key and body replay the first response"
+// @Param request body ApplicationRequest true "Basket and coupon code"
+// @Success 200 {object} reviewsch_internal_service_entity.Basket
+// @Router /v1/coupons/reserve [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Personal coupon of another customer"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 422 {object} ErrorResponse "Coupon cannot be applied"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Reserve(c *gin.Context) {
+	apiReq := ApplicationRequest{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	basketReq, err := apiReq.Basket.ToEntity()
+	if err != nil {
+		invalidRequest(c, err.Error())
+		return
+	}
+
+	basket, err := h.svc.ReserveCoupon(basketReq, apiReq.Code, customer(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, basket)
+}
+
+// Commit godoc
+// @Summary Commit a coupon reservation
+// @Description Redeem a reserved coupon once the order is placed; only the customer who reserved it can commit it
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} SuccessResponse
+// @Router /v1/coupons/reservations/{id}/commit [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 410 {object} ErrorResponse "Reservation expired"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Commit(c *gin.Context) {
+	if err := h.svc.CommitReservation(c.Param("id"), customer(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reservation committed successfully",
+	})
+}
+
+// Release godoc
+// @Summary Release a coupon reservation
+// @Description Free a reserved coupon when the order is cancelled and give its discount back to the campaign; only the customer who reserved it can release it
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} SuccessResponse
+// @Router /v1/coupons/reservations/{id}/release [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Release(c *gin.Context) {
+	if err := h.svc.ReleaseReservation(c.Param("id"), customer(c)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reservation released successfully",
+	})
+}
+
+// ErrorResponse Define response structures for Swagger. Errors are
+// written as application/problem+json.
+type ErrorResponse problem.Problem
+
 type SuccessResponse struct {
 	Message string `json:"message"`
 }
+
+// customer identifies the caller from the JWT claims set by the auth
+// middleware
+func customer(c *gin.Context) entity.Customer {
+	return entity.Customer{
+		ID:   c.GetString("userID"),
+		Role: c.GetString("role"),
+	}
+}
+
+// actor identifies who changes coupons in the request, for the audit log
+func actor(c *gin.Context) entity.Actor {
+	return entity.Actor{
+		ID:        c.GetString("userID"),
+		Role:      c.GetString("role"),
+		RequestID: trace.ID(c),
+	}
+}
+
+// Activate godoc
+// @Summary Activate a coupon
+// @Description Make a draft coupon applicable
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Success 200 {object} reviewsch_internal_service_entity.Coupon
+// @Router /v1/coupons/{code}/activate [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Activate(c *gin.Context) {
+	h.changeStatus(c, h.svc.ActivateCoupon)
+}
+
+// Pause godoc
+// @Summary Pause a coupon
+// @Description Temporarily stop an active coupon from being applied, e.g. when its code leaked
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Success 200 {object} reviewsch_internal_service_entity.Coupon
+// @Router /v1/coupons/{code}/pause [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Pause(c *gin.Context) {
+	h.changeStatus(c, h.svc.PauseCoupon)
+}
+
+// Resume godoc
+// @Summary Resume a coupon
+// @Description Make a paused coupon applicable again
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Success 200 {object} reviewsch_internal_service_entity.Coupon
+// @Router /v1/coupons/{code}/resume [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Resume(c *gin.Context) {
+	h.changeStatus(c, h.svc.ResumeCoupon)
+}
+
+// Archive godoc
+// @Summary Archive a coupon
+// @Description Stop a coupon from being applied for good; the coupon and its redemptions are kept
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Success 200 {object} reviewsch_internal_service_entity.Coupon
+// @Router /v1/coupons/{code}/archive [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 409 {object} ErrorResponse "Conflict with the current state"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Archive(c *gin.Context) {
+	h.changeStatus(c, h.svc.ArchiveCoupon)
+}
+
+// changeStatus moves the coupon in the path to another lifecycle status
+func (h *CouponHandler) changeStatus(c *gin.Context, change func(string, entity.Actor) (*entity.Coupon, error)) {
+	coupon, err := change(c.Param("code"), actor(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
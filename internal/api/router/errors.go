@@ -0,0 +1,129 @@
+package router
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"reviewsch/internal/api/middleware/trace"
+	"reviewsch/internal/api/problem"
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reason codes are stable identifiers of error causes that clients can
+// localize. Their messages may change, the codes do not. Codes shared with
+// the middleware live in the problem package.
+const (
+	ReasonInvalidCoupon           = "invalid_coupon"
+	ReasonInvalidRate             = "invalid_rate"
+	ReasonInvalidCursor           = "invalid_cursor"
+	ReasonCustomerRequired        = "customer_required"
+	ReasonCouponNotAssigned       = "coupon_not_assigned"
+	ReasonCurrencyMismatch        = "currency_mismatch"
+	ReasonCouponNotFound          = "coupon_not_found"
+	ReasonCouponVersionNotFound   = "coupon_version_not_found"
+	ReasonCampaignNotFound        = "campaign_not_found"
+	ReasonReservationNotFound     = "reservation_not_found"
+	ReasonRateNotFound            = "rate_not_found"
+	ReasonCouponExists            = "coupon_exists"
+	ReasonVersionConflict         = "version_conflict"
+	ReasonVersionRequired         = "version_required"
+	ReasonInvalidTransition       = "invalid_transition"
+	ReasonRedemptionLimitReached  = "redemption_limit_reached"
+	ReasonCustomerLimitReached    = "customer_limit_reached"
+	ReasonCampaignBudgetExhausted = "campaign_budget_exhausted"
+	ReasonReservationExpired      = "reservation_expired"
+	ReasonCouponExpired           = "coupon_expired"
+	ReasonCouponNotYetActive      = "coupon_not_yet_active"
+	ReasonCouponNotActive         = "coupon_not_active"
+	ReasonCampaignPaused          = "campaign_paused"
+	ReasonBelowMinBasketValue     = "below_min_basket_value"
+	ReasonNoEligibleItems         = "no_eligible_items"
+	ReasonConditionsNotMet        = "conditions_not_met"
+	ReasonCouponNotCombinable     = "coupon_not_combinable"
+	ReasonExclusivityConflict     = "exclusivity_conflict"
+	ReasonNoAddedDiscount         = "no_added_discount"
+)
+
+// serviceErrors maps the service errors to a status and reason code. The
+// first match wins, so more specific errors come first.
+var serviceErrors = []struct {
+	err    error
+	status int
+	reason string
+}{
+	{service.ErrCouponNotFound, http.StatusNotFound, ReasonCouponNotFound},
+	{service.ErrCouponVersionNotFound, http.StatusNotFound, ReasonCouponVersionNotFound},
+	{service.ErrCampaignNotFound, http.StatusNotFound, ReasonCampaignNotFound},
+	{service.ErrReservationNotFound, http.StatusNotFound, ReasonReservationNotFound},
+	{service.ErrRateNotFound, http.StatusNotFound, ReasonRateNotFound},
+
+	{service.ErrCouponNotAssigned, http.StatusForbidden, ReasonCouponNotAssigned},
+
+	{service.ErrCouponExists, http.StatusConflict, ReasonCouponExists},
+	{service.ErrVersionConflict, http.StatusConflict, ReasonVersionConflict},
+	{service.ErrInvalidTransition, http.StatusConflict, ReasonInvalidTransition},
+	{service.ErrCustomerLimitReached, http.StatusConflict, ReasonCustomerLimitReached},
+	{service.ErrRedemptionLimitReached, http.StatusConflict, ReasonRedemptionLimitReached},
+	{service.ErrCampaignBudgetExhausted, http.StatusConflict, ReasonCampaignBudgetExhausted},
+	{service.ErrReservationExpired, http.StatusGone, ReasonReservationExpired},
+
+	{service.ErrCouponExpired, http.StatusUnprocessableEntity, ReasonCouponExpired},
+	{service.ErrCouponNotYetActive, http.StatusUnprocessableEntity, ReasonCouponNotYetActive},
+	{service.ErrCouponNotActive, http.StatusUnprocessableEntity, ReasonCouponNotActive},
+	{service.ErrCampaignPaused, http.StatusUnprocessableEntity, ReasonCampaignPaused},
+	{service.ErrBelowMinBasketValue, http.StatusUnprocessableEntity, ReasonBelowMinBasketValue},
+	{service.ErrNoEligibleItems, http.StatusUnprocessableEntity, ReasonNoEligibleItems},
+	{service.ErrRuleNotSatisfied, http.StatusUnprocessableEntity, ReasonConditionsNotMet},
+	{service.ErrCouponNotCombinable, http.StatusUnprocessableEntity, ReasonCouponNotCombinable},
+	{service.ErrExclusivityConflict, http.StatusUnprocessableEntity, ReasonExclusivityConflict},
+	{service.ErrNoAddedDiscount, http.StatusUnprocessableEntity, ReasonNoAddedDiscount},
+
+	{service.ErrInvalidRequest, http.StatusBadRequest, problem.ReasonInvalidRequest},
+	{service.ErrInvalidCoupon, http.StatusBadRequest, ReasonInvalidCoupon},
+	{service.ErrInvalidRate, http.StatusBadRequest, ReasonInvalidRate},
+	{service.ErrInvalidCursor, http.StatusBadRequest, ReasonInvalidCursor},
+	{service.ErrCustomerRequired, http.StatusBadRequest, ReasonCustomerRequired},
+	{service.ErrCurrencyMismatch, http.StatusBadRequest, ReasonCurrencyMismatch},
+}
+
+// errorStatus returns the status and reason code of a service error.
+// Unknown errors, such as repository failures, are internal errors.
+func errorStatus(err error) (int, string) {
+	for _, known := range serviceErrors {
+		if errors.Is(err, known.err) {
+			return known.status, known.reason
+		}
+	}
+	return http.StatusInternalServerError, problem.ReasonInternalError
+}
+
+// dropReasons fills in the reason codes of dropped coupons.
+func dropReasons(dropped []entity.DroppedCoupon) {
+	for i := range dropped {
+		_, dropped[i].Reason = errorStatus(dropped[i].Err)
+	}
+}
+
+// respondError writes the response for a failed service call. Details of
+// internal errors are logged instead of returned.
+func respondError(c *gin.Context, err error) {
+	status, reason := errorStatus(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		log.Printf("%s %s [%s]: %v", c.Request.Method, c.Request.URL.Path, trace.ID(c), err)
+		message = "internal error"
+	}
+	writeError(c, status, reason, message)
+}
+
+// invalidRequest rejects a request that could not be read
+func invalidRequest(c *gin.Context, message string) {
+	writeError(c, http.StatusBadRequest, problem.ReasonInvalidRequest, message)
+}
+
+func writeError(c *gin.Context, status int, reason, message string) {
+	problem.Write(c, status, reason, message)
+}
@@ -0,0 +1,161 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Versions godoc
+// @Summary List coupon versions
+// @Description Retrieve every version of a coupon's terms, oldest first
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Success 200 {array} reviewsch_internal_service_entity.CouponVersion
+// @Router /v1/coupons/{code}/versions [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Versions(c *gin.Context) {
+	versions, err := h.svc.ListCouponVersions(c.Param("code"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// Version godoc
+// @Summary Get a coupon version
+// @Description Retrieve a version of a coupon's terms by number
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Param version path int true "Coupon version"
+// @Success 200 {object} reviewsch_internal_service_entity.CouponVersion
+// @Router /v1/coupons/{code}/versions/{version} [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Version(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	coupon, err := h.svc.GetCouponVersion(c.Param("code"), version)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// AsOf godoc
+// @Summary Get a coupon as of a time
+// @Description Retrieve the version of a coupon's terms in effect at the given time, e.g. when an order applied it; not found while the coupon was deleted
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Param at query string true "Point in time (RFC 3339)"
+// @Success 200 {object} reviewsch_internal_service_entity.CouponVersion
+// @Router /v1/coupons/{code}/as-of [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) AsOf(c *gin.Context) {
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	coupon, err := h.svc.GetCouponAsOf(c.Param("code"), at)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// VersionByID godoc
+// @Summary Get a coupon version by ID
+// @Description Retrieve the coupon terms named by the couponVersionId of an application result
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param id path string true "Coupon version ID"
+// @Success 200 {object} reviewsch_internal_service_entity.CouponVersion
+// @Router /v1/coupons/versions/{id} [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) VersionByID(c *gin.Context) {
+	coupon, err := h.svc.GetCouponVersionByID(c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// Diff godoc
+// @Summary Compare coupon versions
+// @Description List the coupon fields that changed from one version to another
+// @Tags Coupons
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param code path string true "Coupon code"
+// @Param from query int true "Earlier version"
+// @Param to query int true "Later version"
+// @Success 200 {object} reviewsch_internal_service_entity.CouponDiff
+// @Router /v1/coupons/{code}/diff [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *CouponHandler) Diff(c *gin.Context) {
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil {
+		invalidRequest(c, "Invalid request format: from: "+err.Error())
+		return
+	}
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil {
+		invalidRequest(c, "Invalid request format: to: "+err.Error())
+		return
+	}
+
+	diff, err := h.svc.DiffCouponVersions(c.Param("code"), from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
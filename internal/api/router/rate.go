@@ -0,0 +1,117 @@
+package router
+
+import (
+	"net/http"
+	. "reviewsch/internal/api/dto/entity"
+	"reviewsch/internal/api/handler"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateHandler handles exchange-rate operations
+type RateHandler struct {
+	svc handler.Service
+}
+
+// NewRateHandler creates a new RateHandler instance
+func NewRateHandler(svc handler.Service) *RateHandler {
+	return &RateHandler{
+		svc: svc,
+	}
+}
+
+// Load godoc
+// @Summary Load exchange rates
+// @Description Store a new version of the exchange-rate table; coupons applied from validFrom on are converted with it
+// @Tags Rates
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param rates body RateTable true "Exchange rates"
+// @Success 201 {object} reviewsch_internal_service_entity.RateTable
+// @Router /v1/rates [post]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *RateHandler) Load(c *gin.Context) {
+	apiReq := RateTable{}
+	if err := c.ShouldBindJSON(&apiReq); err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	table, err := h.svc.LoadRates(apiReq.ToEntity())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, table)
+}
+
+// Get godoc
+// @Summary Get exchange rates
+// @Description Retrieve the exchange-rate table in effect now or at the given time
+// @Tags Rates
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param at query string false "Point in time (RFC 3339)"
+// @Success 200 {object} reviewsch_internal_service_entity.RateTable
+// @Router /v1/rates [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *RateHandler) Get(c *gin.Context) {
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			invalidRequest(c, "Invalid request format: "+err.Error())
+			return
+		}
+	}
+
+	table, err := h.svc.GetRates(at)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, table)
+}
+
+// GetVersion godoc
+// @Summary Get an exchange-rate version
+// @Description Retrieve a version of the exchange-rate table, e.g. the one reported with an applied coupon
+// @Tags Rates
+// @Produce json
+// @Param Authorization header string true "Bearer JWT token"
+// @Param version path int true "Rate table version"
+// @Success 200 {object} reviewsch_internal_service_entity.RateTable
+// @Router /v1/rates/{version} [get]
+// @Security Bearer
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+func (h *RateHandler) GetVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		invalidRequest(c, "Invalid request format: "+err.Error())
+		return
+	}
+
+	table, err := h.svc.GetRateVersion(version)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, table)
+}
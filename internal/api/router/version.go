@@ -0,0 +1,59 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reviewsch/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats a coupon version as an entity tag
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// expectedVersion returns the coupon version a change is based on, taken
+// from the If-Match header or else from the request. header reports
+// whether it came from If-Match.
+func expectedVersion(c *gin.Context, requested int) (version int, header bool, err error) {
+	match := strings.TrimSpace(c.GetHeader("If-Match"))
+	if match == "" {
+		if requested <= 0 {
+			return 0, false, errMissingVersion
+		}
+		return requested, false, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(match, "W/"), `"`)
+	version, err = strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return 0, true, fmt.Errorf("invalid If-Match header %q", match)
+	}
+	return version, true, nil
+}
+
+var errMissingVersion = errors.New("If-Match header or version required")
+
+// versionRequired rejects a change without a usable version
+func versionRequired(c *gin.Context, err error) {
+	if errors.Is(err, errMissingVersion) {
+		writeError(c, http.StatusPreconditionRequired, ReasonVersionRequired, err.Error())
+		return
+	}
+	invalidRequest(c, err.Error())
+}
+
+// versionError writes the response for a failed versioned change. A stale
+// If-Match header fails its precondition; a stale version in the request
+// conflicts with the current coupon.
+func versionError(c *gin.Context, err error, header bool) {
+	if header && errors.Is(err, service.ErrVersionConflict) {
+		writeError(c, http.StatusPreconditionFailed, ReasonVersionConflict, err.Error())
+		return
+	}
+	respondError(c, err)
+}
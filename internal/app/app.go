@@ -23,22 +23,43 @@ import (
 )
 
 var (
-	repo = memdb.New()
+	repo      = memdb.New()
+	ledger    = memdb.NewLedger()
+	campaigns = memdb.NewCampaignRepository()
+	rates     = memdb.NewRateRepository()
+	audit     = memdb.NewAuditLog()
+	history   = memdb.NewCouponHistory()
 )
 
 func Run() error {
 	swagger.SetupSwagger()
 
 	conf := config.NewDefault()
+	if err := conf.Idempotency.Validate(); err != nil {
+		return fmt.Errorf("IDEMPOTENCY_STORE: %w", err)
+	}
+	if err := config.LoadCurrencyRounding(); err != nil {
+		return err
+	}
 	gateway := handler.New(*conf)
 
 	// Swagger documentation
 	gateway.Engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Register services
-	couponService := service.New(repo)
+	couponService := service.New(repo, ledger, campaigns, rates, audit, history)
 	gateway.RegisterService("coupon", couponService)
 
+	table, err := config.LoadExchangeRates()
+	if err != nil {
+		return err
+	}
+	if table != nil {
+		if _, err := couponService.LoadRates(*table); err != nil {
+			return fmt.Errorf("EXCHANGE_RATES_FILE: %w", err)
+		}
+	}
+
 	// Register middleware
 	gateway.UseMiddleware(handler.CORSMiddleware(*conf))
 
@@ -59,10 +80,68 @@ func setupRoutes(gateway *handler.Gateway, couponService *service.Service) {
 
 	// Applied JWT middleware to all coupon routes
 	coupons.Use(auth.AdminAuth())
+	// Retries of requests with side effects replay the first response
+	idempotent := gateway.Idempotency()
+	{
+		coupons.POST("/apply", idempotent, couponHandler.Apply)
+		coupons.POST("/apply-multiple", idempotent, couponHandler.ApplyMultiple)
+		coupons.POST("/validate", couponHandler.Validate)
+		coupons.POST("/applicable", couponHandler.Applicable)
+		coupons.POST("/recommend", couponHandler.Recommend)
+		coupons.GET("/mine", couponHandler.Mine)
+		coupons.POST("/reserve", idempotent, couponHandler.Reserve)
+		coupons.POST("/reservations/:id/commit", couponHandler.Commit)
+		coupons.POST("/reservations/:id/release", couponHandler.Release)
+	}
+
+	// Reading and changing coupon definitions is reserved to admins; they
+	// include personal coupons with the customers they are assigned to
+	manage := coupons.Group("", auth.RequireRole(auth.RoleAdmin))
+	{
+		manage.GET("", couponHandler.List)
+		manage.GET("/", couponHandler.Get)
+		manage.GET("/versions/:id", couponHandler.VersionByID)
+		manage.GET("/:code", couponHandler.GetOne)
+		manage.GET("/:code/versions", couponHandler.Versions)
+		manage.GET("/:code/versions/:version", couponHandler.Version)
+		manage.GET("/:code/as-of", couponHandler.AsOf)
+		manage.GET("/:code/diff", couponHandler.Diff)
+		manage.POST("/create", idempotent, couponHandler.Create)
+		manage.POST("/generate", idempotent, couponHandler.Generate)
+		manage.PUT("/:code", couponHandler.Replace)
+		manage.PATCH("/:code", couponHandler.Modify)
+		manage.DELETE("/:code", couponHandler.Delete)
+		manage.POST("/:code/activate", couponHandler.Activate)
+		manage.POST("/:code/pause", couponHandler.Pause)
+		manage.POST("/:code/resume", couponHandler.Resume)
+		manage.POST("/:code/archive", couponHandler.Archive)
+	}
+
+	// Campaigns group
+	campaignHandler := router.NewCampaignHandler(couponService)
+	campaignGroup := v1.Group("/campaigns")
+	campaignGroup.Use(auth.AdminAuth(), auth.RequireRole(auth.RoleAdmin))
+	{
+		campaignGroup.POST("", campaignHandler.Create)
+		campaignGroup.GET("/:id", campaignHandler.Get)
+	}
+
+	// Exchange rates group
+	rateHandler := router.NewRateHandler(couponService)
+	rateGroup := v1.Group("/rates")
+	rateGroup.Use(auth.AdminAuth())
+	{
+		rateGroup.POST("", auth.RequireRole(auth.RoleAdmin), rateHandler.Load)
+		rateGroup.GET("", rateHandler.Get)
+		rateGroup.GET("/:version", rateHandler.GetVersion)
+	}
+
+	// Audit log
+	auditHandler := router.NewAuditHandler(couponService)
+	auditGroup := v1.Group("/audit")
+	auditGroup.Use(auth.AdminAuth(), auth.RequireRole(auth.RoleAdmin))
 	{
-		coupons.POST("/apply", couponHandler.Apply)
-		coupons.POST("/create", couponHandler.Create)
-		coupons.GET("/", couponHandler.Get)
+		auditGroup.GET("", auditHandler.List)
 	}
 
 	// Health check
@@ -1,13 +1,15 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
-	ratelimit "github.com/JGLTechnologies/gin-rate-limit"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"os"
 	"path/filepath"
+	dto "reviewsch/internal/api/dto/entity"
 	"reviewsch/internal/api/handler"
+	"reviewsch/internal/service/entity"
 	"strconv"
 	"strings"
 	"time"
@@ -85,6 +87,48 @@ func getEnvAsSlice(key string, defaultValue []string, sep string) []string {
 	return defaultValue
 }
 
+// LoadCurrencyRounding applies the CURRENCY_ROUNDING overrides to the
+// known currencies. The value is a comma separated list of currency=mode
+// pairs, e.g. "EUR=half_even,JPY=down".
+func LoadCurrencyRounding() error {
+	for _, pair := range getEnvAsSlice("CURRENCY_ROUNDING", nil, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, mode, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("CURRENCY_ROUNDING: invalid entry %q", pair)
+		}
+		if err := entity.SetRounding(strings.TrimSpace(code), entity.RoundingMode(strings.TrimSpace(mode))); err != nil {
+			return fmt.Errorf("CURRENCY_ROUNDING: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadExchangeRates reads the exchange-rate table from the JSON file named
+// by EXCHANGE_RATES_FILE, in the format accepted by POST /v1/rates. It
+// returns nil when no file is configured.
+func LoadExchangeRates() (*entity.RateTable, error) {
+	path := getEnv("EXCHANGE_RATES_FILE", "")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("EXCHANGE_RATES_FILE: %w", err)
+	}
+	var table dto.RateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("EXCHANGE_RATES_FILE: %w", err)
+	}
+
+	rates := table.ToEntity()
+	return &rates, nil
+}
+
 func LoadConfig() (*handler.Config, error) {
 	// Required value check
 	redisPassword := os.Getenv("REDIS_PASSWORD")
@@ -100,7 +144,7 @@ func LoadConfig() (*handler.Config, error) {
 
 		// CORS configuration
 		AllowedMethods: getEnvAsSlice("CORS_ALLOWED_METHODS",
-			[]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, ","),
+			[]string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}, ","),
 		AllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS",
 			[]string{"*"}, ","),
 
@@ -115,12 +159,14 @@ func LoadConfig() (*handler.Config, error) {
 			KeyFunc: func(c *gin.Context) string {
 				return c.ClientIP()
 			},
-			ErrorHandler: func(c *gin.Context, info ratelimit.Info) {
-				c.JSON(429, gin.H{
-					"error": fmt.Sprintf("Too many requests. Try again in %v",
-						time.Until(info.ResetTime)),
-				})
-			},
+			ErrorHandler: handler.RateLimitExceeded,
+		},
+
+		// Idempotency key configuration
+		Idempotency: handler.IdempotencyConfig{
+			Enabled: getEnvAsBool("IDEMPOTENCY_ENABLED", true),
+			Store:   getEnv("IDEMPOTENCY_STORE", "memory"),
+			TTL:     getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour),
 		},
 	}, nil
 }
@@ -140,7 +186,7 @@ func getDefaultConfig() *handler.Config {
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		MaxHeaderBytes: 1 << 20,
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedOrigins: []string{"*"},
 		RateLimit: handler.RateLimitConfig{
 			Enabled:    true,
@@ -151,12 +197,12 @@ func getDefaultConfig() *handler.Config {
 			KeyFunc: func(c *gin.Context) string {
 				return c.ClientIP()
 			},
-			ErrorHandler: func(c *gin.Context, info ratelimit.Info) {
-				c.JSON(429, gin.H{
-					"error": fmt.Sprintf("Too many requests. Try again in %v",
-						time.Until(info.ResetTime)),
-				})
-			},
+			ErrorHandler: handler.RateLimitExceeded,
+		},
+		Idempotency: handler.IdempotencyConfig{
+			Enabled: true,
+			Store:   "memory",
+			TTL:     24 * time.Hour,
 		},
 	}
 }
@@ -0,0 +1,48 @@
+package memdb
+
+import (
+	"reviewsch/internal/service/entity"
+	"slices"
+	"sync"
+)
+
+// AuditLog is an in-memory, append-only store of audit entries
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []entity.AuditEntry
+}
+
+// NewAuditLog creates an empty audit log
+func NewAuditLog() *AuditLog {
+	return &AuditLog{}
+}
+
+// Append stores the entries in order
+func (l *AuditLog) Append(entries ...entity.AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.Changes = slices.Clone(entry.Changes)
+		l.entries = append(l.entries, entry)
+	}
+	return nil
+}
+
+// Query returns up to query.Limit matching entries, the last appended first
+func (l *AuditLog) Query(query entity.AuditQuery) ([]entity.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entries []entity.AuditEntry
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		if query.Limit > 0 && len(entries) == query.Limit {
+			break
+		}
+		if entry := l.entries[i]; query.Matches(entry) {
+			entry.Changes = slices.Clone(entry.Changes)
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,49 @@
+package memdb
+
+import (
+	"reviewsch/internal/service/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog_Query(t *testing.T) {
+	log := NewAuditLog()
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	err := log.Append(
+		entity.AuditEntry{ID: "1", Code: "A", Action: entity.AuditCreate, ActorID: "admin", At: day},
+		entity.AuditEntry{ID: "2", Code: "B", Action: entity.AuditCreate, ActorID: "admin", At: day.Add(time.Hour)},
+		entity.AuditEntry{ID: "3", Code: "A", Action: entity.AuditUpdate, ActorID: "ops", At: day.Add(2 * time.Hour)},
+		entity.AuditEntry{ID: "4", Code: "A", Action: entity.AuditStatus, ActorID: "admin", At: day.Add(3 * time.Hour)},
+	)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		query    entity.AuditQuery
+		expected []string
+	}{
+		{name: "all, newest first", query: entity.AuditQuery{}, expected: []string{"4", "3", "2", "1"}},
+		{name: "by code", query: entity.AuditQuery{Code: "A"}, expected: []string{"4", "3", "1"}},
+		{name: "by actor", query: entity.AuditQuery{ActorID: "ops"}, expected: []string{"3"}},
+		{name: "by action", query: entity.AuditQuery{Action: entity.AuditCreate}, expected: []string{"2", "1"}},
+		{name: "time range", query: entity.AuditQuery{From: day.Add(time.Hour), To: day.Add(3 * time.Hour)}, expected: []string{"3", "2"}},
+		{name: "limit", query: entity.AuditQuery{Code: "A", Limit: 2}, expected: []string{"4", "3"}},
+		{name: "no match", query: entity.AuditQuery{Code: "C"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := log.Query(tt.query)
+			assert.NoError(t, err)
+
+			var ids []string
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
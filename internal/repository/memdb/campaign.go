@@ -0,0 +1,99 @@
+package memdb
+
+import (
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+	"sync"
+)
+
+// CampaignRepository is an in-memory campaign store
+type CampaignRepository struct {
+	mu      sync.Mutex
+	entries map[string]entity.Campaign
+}
+
+// NewCampaignRepository creates an empty campaign store
+func NewCampaignRepository() *CampaignRepository {
+	return &CampaignRepository{
+		entries: make(map[string]entity.Campaign),
+	}
+}
+
+func (r *CampaignRepository) FindByID(id string) (*entity.Campaign, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	campaign, ok := r.entries[id]
+	if !ok {
+		return nil, service.ErrCampaignNotFound
+	}
+	return &campaign, nil
+}
+
+func (r *CampaignRepository) Save(campaign entity.Campaign) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[campaign.ID] = campaign
+	return nil
+}
+
+// Spend charges one redemption worth amount to the campaign. The budgets
+// are checked and updated under a single lock so concurrent redemptions
+// cannot overspend, and the campaign pauses once a budget is used up.
+func (r *CampaignRepository) Spend(id string, amount entity.Money) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	campaign, ok := r.entries[id]
+	if !ok {
+		return service.ErrCampaignNotFound
+	}
+	if campaign.Paused {
+		return service.ErrCampaignPaused
+	}
+	if amount.Currency != campaign.Currency {
+		return service.ErrCurrencyMismatch
+	}
+
+	spent := campaign.DiscountSpent.Add(amount)
+	if campaign.DiscountBudget.Amount > 0 && spent.Amount > campaign.DiscountBudget.Amount {
+		return service.ErrCampaignBudgetExhausted
+	}
+	if campaign.RedemptionBudget > 0 && campaign.Redemptions >= campaign.RedemptionBudget {
+		return service.ErrCampaignBudgetExhausted
+	}
+
+	campaign.DiscountSpent = spent
+	campaign.Redemptions++
+	campaign.Paused = campaign.Exhausted()
+	r.entries[id] = campaign
+	return nil
+}
+
+// Refund gives back one redemption worth amount and resumes the campaign
+// if it was paused because of its budget.
+func (r *CampaignRepository) Refund(id string, amount entity.Money) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	campaign, ok := r.entries[id]
+	if !ok {
+		return service.ErrCampaignNotFound
+	}
+
+	if amount.Currency != campaign.Currency {
+		return service.ErrCurrencyMismatch
+	}
+
+	campaign.DiscountSpent = campaign.DiscountSpent.Sub(amount)
+	if campaign.DiscountSpent.Amount < 0 {
+		campaign.DiscountSpent.Amount = 0
+	}
+	if campaign.Redemptions > 0 {
+		campaign.Redemptions--
+	}
+	campaign.Paused = campaign.Exhausted()
+	r.entries[id] = campaign
+	return nil
+}
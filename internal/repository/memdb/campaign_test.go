@@ -0,0 +1,127 @@
+package memdb
+
+import (
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eur converts whole euros into money
+func eur(units int64) entity.Money {
+	return entity.Money{Amount: units * 100, Currency: "EUR"}
+}
+
+func TestCampaignRepository_Spend(t *testing.T) {
+	tests := []struct {
+		name         string
+		campaign     entity.Campaign
+		amount       entity.Money
+		wantErr      error
+		expectSpent  entity.Money
+		expectPaused bool
+	}{
+		{
+			name:        "unlimited campaign",
+			campaign:    entity.Campaign{ID: "c1", Currency: "EUR"},
+			amount:      eur(10),
+			expectSpent: eur(10),
+		},
+		{
+			name:         "discount budget used up",
+			campaign:     entity.Campaign{ID: "c1", Currency: "EUR", DiscountBudget: eur(100), DiscountSpent: eur(90)},
+			amount:       eur(10),
+			expectSpent:  eur(100),
+			expectPaused: true,
+		},
+		{
+			name:        "discount budget exceeded",
+			campaign:    entity.Campaign{ID: "c1", Currency: "EUR", DiscountBudget: eur(100), DiscountSpent: eur(95)},
+			amount:      eur(10),
+			wantErr:     service.ErrCampaignBudgetExhausted,
+			expectSpent: eur(95),
+		},
+		{
+			name:         "redemption budget used up",
+			campaign:     entity.Campaign{ID: "c1", Currency: "EUR", RedemptionBudget: 2, Redemptions: 1},
+			amount:       eur(10),
+			expectSpent:  eur(10),
+			expectPaused: true,
+		},
+		{
+			name:         "paused campaign",
+			campaign:     entity.Campaign{ID: "c1", Currency: "EUR", Paused: true},
+			amount:       eur(10),
+			wantErr:      service.ErrCampaignPaused,
+			expectPaused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewCampaignRepository()
+			assert.NoError(t, repo.Save(tt.campaign))
+
+			err := repo.Spend("c1", tt.amount)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			campaign, err := repo.FindByID("c1")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectSpent, campaign.DiscountSpent)
+			assert.Equal(t, tt.expectPaused, campaign.Paused)
+		})
+	}
+}
+
+func TestCampaignRepository_Refund(t *testing.T) {
+	repo := NewCampaignRepository()
+	assert.NoError(t, repo.Save(entity.Campaign{ID: "c1", Currency: "EUR", RedemptionBudget: 1}))
+
+	assert.NoError(t, repo.Spend("c1", eur(10)))
+	campaign, _ := repo.FindByID("c1")
+	assert.True(t, campaign.Paused)
+
+	assert.NoError(t, repo.Refund("c1", eur(10)))
+	campaign, _ = repo.FindByID("c1")
+	assert.False(t, campaign.Paused)
+	assert.Equal(t, eur(0), campaign.DiscountSpent)
+	assert.Equal(t, 0, campaign.Redemptions)
+}
+
+func TestCampaignRepository_SpendCurrencyMismatch(t *testing.T) {
+	repo := NewCampaignRepository()
+	assert.NoError(t, repo.Save(entity.Campaign{ID: "c1", Currency: "EUR"}))
+
+	err := repo.Spend("c1", entity.Money{Amount: 1000, Currency: "USD"})
+	assert.ErrorIs(t, err, service.ErrCurrencyMismatch)
+}
+
+func TestCampaignRepository_NotFound(t *testing.T) {
+	repo := NewCampaignRepository()
+
+	_, err := repo.FindByID("missing")
+	assert.ErrorIs(t, err, service.ErrCampaignNotFound)
+	assert.ErrorIs(t, repo.Spend("missing", eur(10)), service.ErrCampaignNotFound)
+}
+
+func TestCampaignRepository_ConcurrentSpend(t *testing.T) {
+	repo := NewCampaignRepository()
+	assert.NoError(t, repo.Save(entity.Campaign{ID: "c1", Currency: "EUR", DiscountBudget: eur(100)}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo.Spend("c1", eur(10))
+		}()
+	}
+	wg.Wait()
+
+	campaign, _ := repo.FindByID("c1")
+	assert.Equal(t, eur(100), campaign.DiscountSpent)
+	assert.Equal(t, 10, campaign.Redemptions)
+	assert.True(t, campaign.Paused)
+}
@@ -0,0 +1,55 @@
+package memdb
+
+import (
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+	"slices"
+	"sync"
+)
+
+// CouponHistory is an in-memory, append-only store of coupon versions
+type CouponHistory struct {
+	mu       sync.Mutex
+	versions map[string][]entity.CouponVersion
+	byID     map[string]entity.CouponVersion
+}
+
+// NewCouponHistory creates an empty coupon history
+func NewCouponHistory() *CouponHistory {
+	return &CouponHistory{
+		versions: make(map[string][]entity.CouponVersion),
+		byID:     make(map[string]entity.CouponVersion),
+	}
+}
+
+// Append stores the versions in order
+func (h *CouponHistory) Append(versions ...entity.CouponVersion) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, version := range versions {
+		h.versions[version.Code] = append(h.versions[version.Code], version)
+		h.byID[version.ID] = version
+	}
+	return nil
+}
+
+// Versions returns the versions of the code, oldest first
+func (h *CouponHistory) Versions(code string) ([]entity.CouponVersion, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return slices.Clone(h.versions[code]), nil
+}
+
+// FindVersion returns the version with the ID
+func (h *CouponHistory) FindVersion(id string) (*entity.CouponVersion, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	version, ok := h.byID[id]
+	if !ok {
+		return nil, service.ErrCouponVersionNotFound
+	}
+	return &version, nil
+}
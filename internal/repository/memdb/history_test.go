@@ -0,0 +1,39 @@
+package memdb
+
+import (
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCouponHistory(t *testing.T) {
+	history := NewCouponHistory()
+	v1 := entity.Coupon{ID: "a", Code: "TEST10", Version: 1, Discount: 10}
+	v2 := entity.Coupon{ID: "a", Code: "TEST10", Version: 2, Discount: 15}
+	other := entity.Coupon{ID: "b", Code: "OTHER", Version: 1, Discount: 5}
+
+	for _, coupon := range []entity.Coupon{v1, other, v2} {
+		err := history.Append(entity.CouponVersion{ID: coupon.VersionID(), Code: coupon.Code, Version: coupon.Version, Coupon: coupon})
+		assert.NoError(t, err)
+	}
+
+	versions, err := history.Versions("TEST10")
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 2) {
+		assert.Equal(t, v1, versions[0].Coupon)
+		assert.Equal(t, v2, versions[1].Coupon)
+	}
+
+	versions, err = history.Versions("MISSING")
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	version, err := history.FindVersion("a-v2")
+	assert.NoError(t, err)
+	assert.Equal(t, 15, version.Coupon.Discount)
+
+	_, err = history.FindVersion("a-v3")
+	assert.ErrorIs(t, err, service.ErrCouponVersionNotFound)
+}
@@ -0,0 +1,188 @@
+package memdb
+
+import (
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+	"sync"
+	"time"
+)
+
+// Ledger is an in-memory record of coupon redemptions and reservations
+type Ledger struct {
+	mu           sync.Mutex
+	redemptions  []entity.Redemption
+	total        map[string]int
+	perCustomer  map[string]map[string]int
+	reservations map[string]entity.Reservation
+}
+
+// NewLedger creates an empty redemption ledger
+func NewLedger() *Ledger {
+	return &Ledger{
+		total:        make(map[string]int),
+		perCustomer:  make(map[string]map[string]int),
+		reservations: make(map[string]entity.Reservation),
+	}
+}
+
+// Redeem records the redemption unless it would exceed the coupon limits.
+// Checking and recording happen under a single lock so concurrent
+// redemptions of the same code cannot overshoot a limit.
+func (l *Ledger) Redeem(redemption entity.Redemption, maxTotal, maxPerCustomer int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.checkLimits(redemption.Code, redemption.CustomerID, redemption.RedeemedAt, maxTotal, maxPerCustomer); err != nil {
+		return err
+	}
+
+	l.record(redemption)
+	return nil
+}
+
+// Reserve holds a redemption unless it would exceed the coupon limits
+func (l *Ledger) Reserve(reservation entity.Reservation, maxTotal, maxPerCustomer int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.checkLimits(reservation.Code, reservation.CustomerID, reservation.ReservedAt, maxTotal, maxPerCustomer); err != nil {
+		return err
+	}
+
+	l.reservations[reservation.ID] = reservation
+	return nil
+}
+
+// Commit turns an unexpired reservation into a redemption
+func (l *Ledger) Commit(id string, at time.Time) (*entity.Redemption, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	reservation, ok := l.reservations[id]
+	if !ok {
+		return nil, service.ErrReservationNotFound
+	}
+	delete(l.reservations, id)
+	if !at.Before(reservation.ExpiresAt) {
+		return nil, service.ErrReservationExpired
+	}
+
+	redemption := entity.Redemption{
+		ID:              reservation.ID,
+		Code:            reservation.Code,
+		CampaignID:      reservation.CampaignID,
+		CustomerID:      reservation.CustomerID,
+		DiscountAmount:  reservation.DiscountAmount,
+		RedeemedAt:      at,
+		CouponVersionID: reservation.CouponVersionID,
+	}
+	l.record(redemption)
+	return &redemption, nil
+}
+
+// FindReservation returns a reservation that has not been committed or
+// released yet
+func (l *Ledger) FindReservation(id string) (*entity.Reservation, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	reservation, ok := l.reservations[id]
+	if !ok {
+		return nil, service.ErrReservationNotFound
+	}
+	return &reservation, nil
+}
+
+// CustomerRedemptions counts the committed redemptions of a customer
+// across all coupons
+func (l *Ledger) CustomerRedemptions(customerID string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := 0
+	for _, customers := range l.perCustomer {
+		count += customers[customerID]
+	}
+	return count, nil
+}
+
+// Usage counts the redemptions of a code and the reservations still active
+// at the given time, in total and by the customer
+func (l *Ledger) Usage(code, customerID string, at time.Time) (int, int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	total, customer := l.usage(code, customerID, at)
+	return total, customer, nil
+}
+
+// Release drops a reservation
+func (l *Ledger) Release(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.reservations[id]; !ok {
+		return service.ErrReservationNotFound
+	}
+	delete(l.reservations, id)
+	return nil
+}
+
+// Expired removes and returns the reservations that expired by the given
+// time
+func (l *Ledger) Expired(at time.Time) ([]entity.Reservation, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var expired []entity.Reservation
+	for id, reservation := range l.reservations {
+		if !at.Before(reservation.ExpiresAt) {
+			expired = append(expired, reservation)
+			delete(l.reservations, id)
+		}
+	}
+	return expired, nil
+}
+
+// checkLimits counts committed redemptions and reservations still active at
+// now against the limits. The caller must hold the lock.
+func (l *Ledger) checkLimits(code, customerID string, now time.Time, maxTotal, maxPerCustomer int) error {
+	total, customer := l.usage(code, customerID, now)
+	if maxTotal > 0 && total >= maxTotal {
+		return service.ErrRedemptionLimitReached
+	}
+	if maxPerCustomer > 0 && customer >= maxPerCustomer {
+		return service.ErrCustomerLimitReached
+	}
+	return nil
+}
+
+// usage counts committed redemptions and reservations still active at now.
+// Expired reservations are left for Expired. The caller must hold the lock.
+func (l *Ledger) usage(code, customerID string, now time.Time) (int, int) {
+	total := l.total[code]
+	customer := l.perCustomer[code][customerID]
+	for _, reservation := range l.reservations {
+		if reservation.Code != code || !now.Before(reservation.ExpiresAt) {
+			continue
+		}
+		total++
+		if reservation.CustomerID == customerID {
+			customer++
+		}
+	}
+	return total, customer
+}
+
+// record appends the redemption and updates the counters. The caller must
+// hold the lock.
+func (l *Ledger) record(redemption entity.Redemption) {
+	customers := l.perCustomer[redemption.Code]
+	if customers == nil {
+		customers = make(map[string]int)
+		l.perCustomer[redemption.Code] = customers
+	}
+	customers[redemption.CustomerID]++
+	l.total[redemption.Code]++
+	l.redemptions = append(l.redemptions, redemption)
+}
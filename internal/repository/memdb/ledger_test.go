@@ -0,0 +1,262 @@
+package memdb
+
+import (
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedger_Redeem(t *testing.T) {
+	tests := []struct {
+		name           string
+		setup          func(*Ledger)
+		redemption     entity.Redemption
+		maxTotal       int
+		maxPerCustomer int
+		wantErr        error
+	}{
+		{
+			name:       "unlimited coupon",
+			setup:      func(l *Ledger) {},
+			redemption: entity.Redemption{Code: "TEST1", CustomerID: "123"},
+		},
+		{
+			name: "global limit reached",
+			setup: func(l *Ledger) {
+				l.total["TEST1"] = 5
+			},
+			redemption: entity.Redemption{Code: "TEST1", CustomerID: "123"},
+			maxTotal:   5,
+			wantErr:    service.ErrRedemptionLimitReached,
+		},
+		{
+			name: "customer limit reached",
+			setup: func(l *Ledger) {
+				l.total["TEST1"] = 1
+				l.perCustomer["TEST1"] = map[string]int{"123": 1}
+			},
+			redemption:     entity.Redemption{Code: "TEST1", CustomerID: "123"},
+			maxTotal:       5,
+			maxPerCustomer: 1,
+			wantErr:        service.ErrCustomerLimitReached,
+		},
+		{
+			name: "other customer within limit",
+			setup: func(l *Ledger) {
+				l.total["TEST1"] = 1
+				l.perCustomer["TEST1"] = map[string]int{"123": 1}
+			},
+			redemption:     entity.Redemption{Code: "TEST1", CustomerID: "456"},
+			maxTotal:       5,
+			maxPerCustomer: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := NewLedger()
+			tt.setup(ledger)
+			before := ledger.total[tt.redemption.Code]
+
+			err := ledger.Redeem(tt.redemption, tt.maxTotal, tt.maxPerCustomer)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, before, ledger.total[tt.redemption.Code])
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, before+1, ledger.total[tt.redemption.Code])
+			assert.Contains(t, ledger.redemptions, tt.redemption)
+		})
+	}
+}
+
+func TestLedger_RedeemConcurrent(t *testing.T) {
+	ledger := NewLedger()
+	const maxTotal = 10
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := ledger.Redeem(entity.Redemption{Code: "HOT"}, maxTotal, 0)
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, maxTotal, succeeded)
+	assert.Len(t, ledger.redemptions, maxTotal)
+}
+
+func TestLedger_Reserve(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	reservation := func(id, customerID string, expiresAt time.Time) entity.Reservation {
+		return entity.Reservation{
+			ID:         id,
+			Code:       "TEST1",
+			CustomerID: customerID,
+			ReservedAt: now,
+			ExpiresAt:  expiresAt,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		setup          func(*Ledger)
+		maxTotal       int
+		maxPerCustomer int
+		wantErr        error
+	}{
+		{
+			name:     "active reservation counts towards global limit",
+			setup:    func(l *Ledger) { l.reservations["r1"] = reservation("r1", "456", now.Add(time.Minute)) },
+			maxTotal: 1,
+			wantErr:  service.ErrRedemptionLimitReached,
+		},
+		{
+			name:           "active reservation counts towards customer limit",
+			setup:          func(l *Ledger) { l.reservations["r1"] = reservation("r1", "123", now.Add(time.Minute)) },
+			maxPerCustomer: 1,
+			wantErr:        service.ErrCustomerLimitReached,
+		},
+		{
+			name:     "expired reservation is freed",
+			setup:    func(l *Ledger) { l.reservations["r1"] = reservation("r1", "456", now) },
+			maxTotal: 1,
+		},
+		{
+			name: "committed redemption counts towards limit",
+			setup: func(l *Ledger) {
+				l.total["TEST1"] = 1
+			},
+			maxTotal: 1,
+			wantErr:  service.ErrRedemptionLimitReached,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ledger := NewLedger()
+			tt.setup(ledger)
+
+			err := ledger.Reserve(reservation("r2", "123", now.Add(time.Minute)), tt.maxTotal, tt.maxPerCustomer)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.NotContains(t, ledger.reservations, "r2")
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Contains(t, ledger.reservations, "r2")
+		})
+	}
+}
+
+func TestLedger_CommitAndRelease(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ledger := NewLedger()
+	for _, id := range []string{"r1", "r2", "r3"} {
+		err := ledger.Reserve(entity.Reservation{
+			ID:         id,
+			Code:       "TEST1",
+			CustomerID: "123",
+			ReservedAt: now,
+			ExpiresAt:  now.Add(time.Minute),
+		}, 0, 0)
+		assert.NoError(t, err)
+	}
+
+	redemption, err := ledger.Commit("r1", now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, "TEST1", redemption.Code)
+	assert.Equal(t, 1, ledger.total["TEST1"])
+	assert.Equal(t, 1, ledger.perCustomer["TEST1"]["123"])
+
+	_, err = ledger.Commit("r1", now.Add(time.Second))
+	assert.ErrorIs(t, err, service.ErrReservationNotFound)
+
+	assert.NoError(t, ledger.Release("r2"))
+	assert.ErrorIs(t, ledger.Release("r2"), service.ErrReservationNotFound)
+
+	_, err = ledger.Commit("r3", now.Add(time.Minute))
+	assert.ErrorIs(t, err, service.ErrReservationExpired)
+	assert.Equal(t, 1, ledger.total["TEST1"])
+	assert.Empty(t, ledger.reservations)
+}
+
+func TestLedger_Expired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ledger := NewLedger()
+	for id, expiresAt := range map[string]time.Time{"r1": now, "r2": now.Add(time.Minute)} {
+		err := ledger.Reserve(entity.Reservation{ID: id, Code: "TEST1", CustomerID: "123", ReservedAt: now, ExpiresAt: expiresAt}, 0, 0)
+		assert.NoError(t, err)
+	}
+
+	// Expired reservations no longer count but wait to be collected
+	total, _, err := ledger.Usage("TEST1", "123", now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Contains(t, ledger.reservations, "r1")
+
+	expired, err := ledger.Expired(now)
+	assert.NoError(t, err)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, "r1", expired[0].ID)
+	assert.NotContains(t, ledger.reservations, "r1")
+	assert.Contains(t, ledger.reservations, "r2")
+
+	expired, err = ledger.Expired(now)
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+}
+
+func TestLedger_CustomerRedemptions(t *testing.T) {
+	l := NewLedger()
+	now := time.Now()
+
+	assert.NoError(t, l.Redeem(entity.Redemption{Code: "TEST1", CustomerID: "123", RedeemedAt: now}, 0, 0))
+	assert.NoError(t, l.Redeem(entity.Redemption{Code: "TEST2", CustomerID: "123", RedeemedAt: now}, 0, 0))
+	assert.NoError(t, l.Redeem(entity.Redemption{Code: "TEST1", CustomerID: "456", RedeemedAt: now}, 0, 0))
+	assert.NoError(t, l.Reserve(entity.Reservation{ID: "r1", Code: "TEST1", CustomerID: "789", ReservedAt: now, ExpiresAt: now.Add(time.Minute)}, 0, 0))
+
+	count, err := l.CustomerRedemptions("123")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = l.CustomerRedemptions("789")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestLedger_Usage(t *testing.T) {
+	l := NewLedger()
+	now := time.Now()
+
+	assert.NoError(t, l.Redeem(entity.Redemption{Code: "TEST1", CustomerID: "123", RedeemedAt: now}, 0, 0))
+	assert.NoError(t, l.Redeem(entity.Redemption{Code: "TEST1", CustomerID: "456", RedeemedAt: now}, 0, 0))
+	assert.NoError(t, l.Redeem(entity.Redemption{Code: "TEST2", CustomerID: "123", RedeemedAt: now}, 0, 0))
+	assert.NoError(t, l.Reserve(entity.Reservation{ID: "r1", Code: "TEST1", CustomerID: "123", ReservedAt: now, ExpiresAt: now.Add(time.Minute)}, 0, 0))
+
+	total, customer, err := l.Usage("TEST1", "123", now)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, 2, customer)
+
+	total, customer, err = l.Usage("TEST1", "123", now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, customer)
+}
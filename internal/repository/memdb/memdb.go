@@ -2,29 +2,220 @@ package memdb
 
 import (
 	"fmt"
+	"reviewsch/internal/service"
 	"reviewsch/internal/service/entity"
+	"slices"
+	"sync"
+	"time"
 )
 
 type Config struct{}
 
+// Repository keeps coupons by code with secondary indexes of the codes by
+// status and of personal coupons by customer, so queries for a status or a
+// customer do not scan every coupon
 type Repository struct {
-	entries map[string]entity.Coupon
+	mu         sync.RWMutex
+	entries    map[string]entity.Coupon
+	byStatus   map[entity.CouponStatus]map[string]bool
+	byCustomer map[string]map[string]bool
 }
 
 func New() *Repository {
 	return &Repository{
-		entries: make(map[string]entity.Coupon),
+		entries:    make(map[string]entity.Coupon),
+		byStatus:   make(map[entity.CouponStatus]map[string]bool),
+		byCustomer: make(map[string]map[string]bool),
 	}
 }
 func (r *Repository) FindByCode(code string) (*entity.Coupon, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	coupon, ok := r.entries[code]
 	if !ok {
-		return nil, fmt.Errorf("coupon not found")
+		return nil, service.ErrCouponNotFound
 	}
 	return &coupon, nil
 }
 
+// Save adds a new coupon. An existing coupon with the same code is never
+// replaced; changes go through Update.
 func (r *Repository) Save(coupon entity.Coupon) error {
-	r.entries[coupon.Code] = coupon
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[coupon.Code]; ok {
+		return fmt.Errorf("%w: %s", service.ErrCouponExists, coupon.Code)
+	}
+	r.put(coupon)
 	return nil
 }
+
+// SaveBatch adds new coupons, all or none of them
+func (r *Repository) SaveBatch(coupons []entity.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool, len(coupons))
+	for _, coupon := range coupons {
+		if _, ok := r.entries[coupon.Code]; ok || seen[coupon.Code] {
+			return fmt.Errorf("%w: %s", service.ErrCouponExists, coupon.Code)
+		}
+		seen[coupon.Code] = true
+	}
+	for _, coupon := range coupons {
+		r.put(coupon)
+	}
+	return nil
+}
+
+// SetStatus changes the status of a coupon if it is still from
+func (r *Repository) SetStatus(code string, from, to entity.CouponStatus) (*entity.Coupon, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	coupon, ok := r.entries[code]
+	if !ok {
+		return nil, service.ErrCouponNotFound
+	}
+	if coupon.Status != from {
+		return nil, fmt.Errorf("%w: coupon is %s", service.ErrInvalidTransition, coupon.Status)
+	}
+	coupon.Status = to
+	coupon.Version++
+	r.put(coupon)
+	return &coupon, nil
+}
+
+// Update replaces a coupon if it is still at version and increments the
+// version
+func (r *Repository) Update(coupon entity.Coupon, version int) (*entity.Coupon, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.entries[coupon.Code]
+	if !ok {
+		return nil, service.ErrCouponNotFound
+	}
+	if current.Version != version {
+		return nil, fmt.Errorf("%w: coupon is at version %d, got %d", service.ErrVersionConflict, current.Version, version)
+	}
+	coupon.Version = version + 1
+	r.put(coupon)
+	return &coupon, nil
+}
+
+// Delete removes a coupon if it is still at version
+func (r *Repository) Delete(code string, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.entries[code]
+	if !ok {
+		return service.ErrCouponNotFound
+	}
+	if current.Version != version {
+		return fmt.Errorf("%w: coupon is at version %d, got %d", service.ErrVersionConflict, current.Version, version)
+	}
+	r.remove(code)
+	return nil
+}
+
+// Query returns the coupons matching the query in its order
+func (r *Repository) Query(query entity.CouponQuery) ([]entity.Coupon, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var coupons []entity.Coupon
+	for _, coupon := range r.withStatus(query.Status) {
+		if query.Matches(coupon) {
+			coupons = append(coupons, coupon)
+		}
+	}
+	slices.SortFunc(coupons, func(a, b entity.Coupon) int {
+		return query.Compare(entity.CursorOf(a), entity.CursorOf(b))
+	})
+	if query.Limit > 0 && len(coupons) > query.Limit {
+		coupons = coupons[:query.Limit]
+	}
+	return coupons, nil
+}
+
+// FindActive returns the active public coupons that can be used at the
+// given time, looked up through the status index
+func (r *Repository) FindActive(at time.Time) ([]entity.Coupon, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var coupons []entity.Coupon
+	for code := range r.byStatus[entity.CouponActive] {
+		if coupon := r.entries[code]; coupon.InWindow(at) && !coupon.Personal() {
+			coupons = append(coupons, coupon)
+		}
+	}
+	return coupons, nil
+}
+
+// FindAssigned returns the personal coupons of the customer, looked up
+// through the customer index
+func (r *Repository) FindAssigned(customerID string) ([]entity.Coupon, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var coupons []entity.Coupon
+	for code := range r.byCustomer[customerID] {
+		coupons = append(coupons, r.entries[code])
+	}
+	return coupons, nil
+}
+
+// withStatus returns the coupons with the status, or all of them for an
+// empty status. The caller must hold the lock.
+func (r *Repository) withStatus(status entity.CouponStatus) map[string]entity.Coupon {
+	if status == "" {
+		return r.entries
+	}
+	coupons := make(map[string]entity.Coupon, len(r.byStatus[status]))
+	for code := range r.byStatus[status] {
+		coupons[code] = r.entries[code]
+	}
+	return coupons
+}
+
+// put stores the coupon and indexes it. The caller must hold the lock.
+func (r *Repository) put(coupon entity.Coupon) {
+	r.remove(coupon.Code)
+	r.entries[coupon.Code] = coupon
+
+	codes := r.byStatus[coupon.Status]
+	if codes == nil {
+		codes = make(map[string]bool)
+		r.byStatus[coupon.Status] = codes
+	}
+	codes[coupon.Code] = true
+
+	for _, customerID := range coupon.CustomerIDs {
+		codes := r.byCustomer[customerID]
+		if codes == nil {
+			codes = make(map[string]bool)
+			r.byCustomer[customerID] = codes
+		}
+		codes[coupon.Code] = true
+	}
+}
+
+// remove drops the coupon and its index entries. The caller must hold the
+// lock.
+func (r *Repository) remove(code string) {
+	if coupon, ok := r.entries[code]; ok {
+		delete(r.byStatus[coupon.Status], code)
+		for _, customerID := range coupon.CustomerIDs {
+			delete(r.byCustomer[customerID], code)
+			if len(r.byCustomer[customerID]) == 0 {
+				delete(r.byCustomer, customerID)
+			}
+		}
+		delete(r.entries, code)
+	}
+}
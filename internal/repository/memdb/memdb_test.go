@@ -1,8 +1,10 @@
 package memdb
 
 import (
+	"reviewsch/internal/service"
 	"reviewsch/internal/service/entity"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -43,7 +45,7 @@ func TestRepository_Save(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "update existing coupon",
+			name: "existing code is not replaced",
 			setup: func(r *Repository) {
 				r.entries = map[string]entity.Coupon{
 					"TEST3": {Code: "TEST3", Discount: 20},
@@ -53,7 +55,7 @@ func TestRepository_Save(t *testing.T) {
 				Code:     "TEST3",
 				Discount: 25,
 			},
-			wantErr: false,
+			wantErr: true,
 		},
 	}
 
@@ -61,10 +63,13 @@ func TestRepository_Save(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := New()
 			tt.setup(repo)
+			before, existed := repo.entries[tt.coupon.Code]
 
 			err := repo.Save(tt.coupon)
 			if tt.wantErr {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, service.ErrCouponExists)
+				assert.True(t, existed)
+				assert.Equal(t, before, repo.entries[tt.coupon.Code], "existing coupon should be kept")
 				return
 			}
 
@@ -166,3 +171,160 @@ func TestRepository_SaveAndFind(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, &coupon, found)
 }
+
+func TestRepository_SaveBatch(t *testing.T) {
+	repo := New()
+	coupons := []entity.Coupon{
+		{Code: "GEN1", Discount: 10, MaxRedemptions: 1},
+		{Code: "GEN2", Discount: 10, MaxRedemptions: 1},
+	}
+
+	err := repo.SaveBatch(coupons)
+	assert.NoError(t, err)
+
+	for _, coupon := range coupons {
+		found, err := repo.FindByCode(coupon.Code)
+		assert.NoError(t, err)
+		assert.Equal(t, coupon, *found)
+	}
+}
+
+func TestRepository_SetStatus(t *testing.T) {
+	repo := New()
+	assert.NoError(t, repo.Save(entity.Coupon{Code: "TEST1", Status: entity.CouponActive}))
+
+	coupon, err := repo.SetStatus("TEST1", entity.CouponActive, entity.CouponPaused)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.CouponPaused, coupon.Status)
+
+	_, err = repo.SetStatus("TEST1", entity.CouponActive, entity.CouponArchived)
+	assert.ErrorIs(t, err, service.ErrInvalidTransition)
+
+	stored, err := repo.FindByCode("TEST1")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.CouponPaused, stored.Status)
+
+	_, err = repo.SetStatus("MISSING", entity.CouponActive, entity.CouponPaused)
+	assert.Error(t, err)
+}
+
+func TestRepository_Update(t *testing.T) {
+	repo := New()
+	assert.NoError(t, repo.Save(entity.Coupon{Code: "TEST1", Discount: 10, Version: 1}))
+
+	updated, err := repo.Update(entity.Coupon{Code: "TEST1", Discount: 15}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+
+	_, err = repo.Update(entity.Coupon{Code: "TEST1", Discount: 20}, 1)
+	assert.ErrorIs(t, err, service.ErrVersionConflict)
+
+	stored, err := repo.FindByCode("TEST1")
+	assert.NoError(t, err)
+	assert.Equal(t, 15, stored.Discount)
+	assert.Equal(t, 2, stored.Version)
+}
+
+func TestRepository_Delete(t *testing.T) {
+	repo := New()
+	assert.NoError(t, repo.Save(entity.Coupon{Code: "TEST1", Version: 3}))
+
+	assert.ErrorIs(t, repo.Delete("TEST1", 2), service.ErrVersionConflict)
+	assert.NoError(t, repo.Delete("TEST1", 3))
+
+	_, err := repo.FindByCode("TEST1")
+	assert.Error(t, err)
+	assert.Error(t, repo.Delete("TEST1", 3))
+}
+
+func TestRepository_SaveBatch_Duplicate(t *testing.T) {
+	repo := New()
+	assert.NoError(t, repo.Save(entity.Coupon{Code: "TAKEN"}))
+
+	err := repo.SaveBatch([]entity.Coupon{{Code: "NEW1"}, {Code: "TAKEN"}})
+	assert.ErrorIs(t, err, service.ErrCouponExists)
+	assert.NotContains(t, repo.entries, "NEW1")
+
+	err = repo.SaveBatch([]entity.Coupon{{Code: "NEW2"}, {Code: "NEW2"}})
+	assert.ErrorIs(t, err, service.ErrCouponExists)
+	assert.NotContains(t, repo.entries, "NEW2")
+}
+
+func TestRepository_Query(t *testing.T) {
+	repo := New()
+	for _, code := range []string{"B", "D", "A", "C"} {
+		assert.NoError(t, repo.Save(entity.Coupon{Code: code, Status: entity.CouponActive}))
+	}
+	assert.NoError(t, repo.Save(entity.Coupon{Code: "E", Status: entity.CouponArchived}))
+
+	coupons, err := repo.Query(entity.CouponQuery{
+		Status: entity.CouponActive,
+		After:  &entity.CouponCursor{Code: "A"},
+		Limit:  2,
+	})
+	assert.NoError(t, err)
+
+	var codes []string
+	for _, coupon := range coupons {
+		codes = append(codes, coupon.Code)
+	}
+	assert.Equal(t, []string{"B", "C"}, codes)
+}
+
+func TestRepository_FindActive(t *testing.T) {
+	now := time.Now()
+	repo := New()
+	assert.NoError(t, repo.SaveBatch([]entity.Coupon{
+		{Code: "ACTIVE", Status: entity.CouponActive},
+		{Code: "DRAFT", Status: entity.CouponDraft},
+		{Code: "LATER", Status: entity.CouponActive, StartsAt: now.Add(time.Hour)},
+		{Code: "EXPIRED", Status: entity.CouponActive, ExpiresAt: now},
+		{Code: "PAUSED", Status: entity.CouponActive},
+		{Code: "GONE", Status: entity.CouponActive, Version: 1},
+		{Code: "PERSONAL", Status: entity.CouponActive, CustomerIDs: []string{"123"}},
+	}))
+	_, err := repo.SetStatus("PAUSED", entity.CouponActive, entity.CouponPaused)
+	assert.NoError(t, err)
+	_, err = repo.SetStatus("DRAFT", entity.CouponDraft, entity.CouponActive)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Delete("GONE", 1))
+
+	coupons, err := repo.FindActive(now)
+	assert.NoError(t, err)
+
+	var codes []string
+	for _, coupon := range coupons {
+		codes = append(codes, coupon.Code)
+	}
+	assert.ElementsMatch(t, []string{"ACTIVE", "DRAFT"}, codes)
+	assert.NotContains(t, repo.byStatus[entity.CouponActive], "PAUSED")
+}
+
+func TestRepository_FindAssigned(t *testing.T) {
+	repo := New()
+	assert.NoError(t, repo.SaveBatch([]entity.Coupon{
+		{Code: "PUBLIC"},
+		{Code: "MINE", CustomerIDs: []string{"123"}, Version: 1},
+		{Code: "SHARED", CustomerIDs: []string{"123", "456"}, Version: 1},
+		{Code: "THEIRS", CustomerIDs: []string{"456"}, Version: 1},
+	}))
+
+	// Reassigned and deleted coupons leave the index
+	_, err := repo.Update(entity.Coupon{Code: "MINE", CustomerIDs: []string{"789"}}, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Delete("THEIRS", 1))
+
+	codes := func(customerID string) []string {
+		coupons, err := repo.FindAssigned(customerID)
+		assert.NoError(t, err)
+		var codes []string
+		for _, coupon := range coupons {
+			codes = append(codes, coupon.Code)
+		}
+		return codes
+	}
+	assert.ElementsMatch(t, []string{"SHARED"}, codes("123"))
+	assert.ElementsMatch(t, []string{"SHARED"}, codes("456"))
+	assert.ElementsMatch(t, []string{"MINE"}, codes("789"))
+	assert.Empty(t, codes("000"))
+}
@@ -0,0 +1,68 @@
+package memdb
+
+import (
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+	"slices"
+	"sync"
+	"time"
+)
+
+// RateRepository is an in-memory store of exchange-rate table versions
+type RateRepository struct {
+	mu     sync.Mutex
+	tables []entity.RateTable
+}
+
+// NewRateRepository creates an empty exchange-rate store
+func NewRateRepository() *RateRepository {
+	return &RateRepository{}
+}
+
+// Save stores the table as the next version
+func (r *RateRepository) Save(table entity.RateTable) (*entity.RateTable, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	table.Version = len(r.tables) + 1
+	table.Rates = slices.Clone(table.Rates)
+	r.tables = append(r.tables, table)
+	return copyTable(table), nil
+}
+
+// Active returns the table with the latest start time not after at. Of
+// tables starting at the same time the latest version wins.
+func (r *RateRepository) Active(at time.Time) (*entity.RateTable, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var active *entity.RateTable
+	for i := range r.tables {
+		table := &r.tables[i]
+		if table.ValidFrom.After(at) {
+			continue
+		}
+		if active == nil || !table.ValidFrom.Before(active.ValidFrom) {
+			active = table
+		}
+	}
+	if active == nil {
+		return nil, service.ErrRateNotFound
+	}
+	return copyTable(*active), nil
+}
+
+func (r *RateRepository) FindByVersion(version int) (*entity.RateTable, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if version < 1 || version > len(r.tables) {
+		return nil, service.ErrRateNotFound
+	}
+	return copyTable(r.tables[version-1]), nil
+}
+
+func copyTable(table entity.RateTable) *entity.RateTable {
+	table.Rates = slices.Clone(table.Rates)
+	return &table
+}
@@ -0,0 +1,65 @@
+package memdb
+
+import (
+	"reviewsch/internal/service"
+	"reviewsch/internal/service/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateRepository_Active(t *testing.T) {
+	repo := NewRateRepository()
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, table := range []entity.RateTable{
+		{ValidFrom: day, Rates: []entity.ExchangeRate{{From: "EUR", To: "PLN", Rate: "4.30"}}},
+		{ValidFrom: day.Add(48 * time.Hour), Rates: []entity.ExchangeRate{{From: "EUR", To: "PLN", Rate: "4.40"}}},
+		{ValidFrom: day, Rates: []entity.ExchangeRate{{From: "EUR", To: "PLN", Rate: "4.31"}}},
+	} {
+		_, err := repo.Save(table)
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		name          string
+		at            time.Time
+		wantErr       error
+		expectVersion int
+	}{
+		{name: "before the first table", at: day.Add(-time.Second), wantErr: service.ErrRateNotFound},
+		{name: "latest version of the same start", at: day.Add(time.Hour), expectVersion: 3},
+		{name: "later start", at: day.Add(72 * time.Hour), expectVersion: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := repo.Active(tt.at)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectVersion, table.Version)
+		})
+	}
+}
+
+func TestRateRepository_FindByVersion(t *testing.T) {
+	repo := NewRateRepository()
+
+	saved, err := repo.Save(entity.RateTable{Rates: []entity.ExchangeRate{{From: "EUR", To: "GBP", Rate: "0.85"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, saved.Version)
+
+	saved.Rates[0].Rate = "1"
+	table, err := repo.FindByVersion(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.85", table.Rates[0].Rate)
+
+	_, err = repo.FindByVersion(2)
+	assert.ErrorIs(t, err, service.ErrRateNotFound)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	. "reviewsch/internal/service/entity"
+	"sort"
+)
+
+// ApplicableCoupons returns every active coupon the basket qualifies for,
+// public or assigned to the customer, with the discount applying it on its
+// own would grant, largest first.
+// Each coupon goes through the same evaluation as ApplyCoupon; nothing is
+// redeemed.
+func (s *Service) ApplicableCoupons(basket Basket, customer Customer) ([]ApplicableCoupon, error) {
+	normalized := basket
+	if err := normalizeBasket(&normalized); err != nil {
+		return nil, err
+	}
+	if normalized.Value.Amount <= 0 {
+		return nil, fmt.Errorf("%w: invalid basket value", ErrInvalidRequest)
+	}
+
+	now := s.now()
+	coupons, err := s.repo.FindActive(now)
+	if err != nil {
+		return nil, err
+	}
+	if customer.ID != "" {
+		assigned, err := s.repo.FindAssigned(customer.ID)
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, assigned...)
+	}
+
+	applicable := []ApplicableCoupon{}
+	for _, candidate := range coupons {
+		coupon, result, err := s.evaluate(basket, candidate.Code, customer, now, nil)
+		if err != nil {
+			if notApplicable(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		found := ApplicableCoupon{
+			Code:           coupon.Code,
+			Type:           coupon.Type,
+			Discount:       coupon.Discount,
+			DiscountAmount: result.DiscountAmount,
+			FinalValue:     result.FinalValue,
+			Stackable:      coupon.Stackable,
+			ExchangeRate:   result.ExchangeRate,
+		}
+		if !coupon.ExpiresAt.IsZero() {
+			found.ExpiresAt = &coupon.ExpiresAt
+		}
+		applicable = append(applicable, found)
+	}
+
+	sort.Slice(applicable, func(i, j int) bool {
+		if applicable[i].DiscountAmount.Amount != applicable[j].DiscountAmount.Amount {
+			return applicable[i].DiscountAmount.Amount > applicable[j].DiscountAmount.Amount
+		}
+		return applicable[i].Code < applicable[j].Code
+	})
+	return applicable, nil
+}
+
+// notApplicable reports whether an evaluation failed because the basket or
+// customer does not qualify for the coupon, rather than because of a fault.
+func notApplicable(err error) bool {
+	for _, target := range []error{
+		ErrCouponNotFound, ErrCouponNotActive, ErrCouponNotYetActive, ErrCouponExpired,
+		ErrCurrencyMismatch, ErrBelowMinBasketValue, ErrCustomerRequired, ErrCouponNotAssigned,
+		ErrRedemptionLimitReached, ErrCustomerLimitReached, ErrRuleNotSatisfied,
+		ErrNoEligibleItems, ErrCampaignPaused, ErrCampaignBudgetExhausted,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	. "reviewsch/internal/service/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultAuditPageSize is the number of audit entries listed when the
+	// query does not set a limit.
+	DefaultAuditPageSize = 100
+	// MaxAuditPageSize is the largest number of audit entries ListAudit
+	// returns.
+	MaxAuditPageSize = 1000
+)
+
+// ListAudit returns the audit entries matching the query, newest first.
+func (s *Service) ListAudit(query AuditQuery) ([]AuditEntry, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultAuditPageSize
+	}
+	if query.Limit > MaxAuditPageSize {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidRequest, MaxAuditPageSize)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidRequest)
+	}
+
+	entries, err := s.audit.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+	return entries, nil
+}
+
+// record keeps the new version of a changed coupon and appends an audit
+// entry for the change. before is nil for a new coupon and after is nil
+// for a deleted one, which is kept as a deleted version. The change has
+// already been stored, so a failure is returned to surface that the
+// history is incomplete.
+func (s *Service) record(actor Actor, action AuditAction, before, after *Coupon) error {
+	now := s.now()
+	var err error
+	switch {
+	case after != nil:
+		err = s.keepVersions(now, *after)
+	case before != nil:
+		err = s.keepDeletion(now, *before)
+	}
+	if err != nil {
+		return err
+	}
+	return s.recordAll(auditEntry(actor, action, before, after, now))
+}
+
+func (s *Service) recordAll(entries ...AuditEntry) error {
+	if err := s.audit.Append(entries...); err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+	return nil
+}
+
+func auditEntry(actor Actor, action AuditAction, before, after *Coupon, at time.Time) AuditEntry {
+	entry := AuditEntry{
+		ID:        uuid.NewString(),
+		Action:    action,
+		ActorID:   actor.ID,
+		ActorRole: actor.Role,
+		RequestID: actor.RequestID,
+		At:        at,
+		Changes:   couponChanges(before, after),
+	}
+	if after != nil {
+		entry.Code, entry.Version = after.Code, after.Version
+	} else if before != nil {
+		entry.Code, entry.Version = before.Code, before.Version
+	}
+	return entry
+}
+
+// couponChanges lists the coupon fields that differ between before and
+// after, either of which may be nil. Version is left out; it is recorded
+// on the entry itself.
+func couponChanges(before, after *Coupon) []FieldChange {
+	var b, a reflect.Value
+	if before != nil {
+		b = reflect.ValueOf(*before)
+	}
+	if after != nil {
+		a = reflect.ValueOf(*after)
+	}
+
+	changes := []FieldChange{}
+	fields := reflect.TypeOf(Coupon{})
+	for i := 0; i < fields.NumField(); i++ {
+		name := fields.Field(i).Name
+		if name == "Version" {
+			continue
+		}
+
+		var change FieldChange
+		var old, current reflect.Value
+		if b.IsValid() {
+			old = b.Field(i)
+			change.Before = old.Interface()
+		}
+		if a.IsValid() {
+			current = a.Field(i)
+			change.After = current.Interface()
+		}
+		if empty(old) && empty(current) {
+			continue
+		}
+		if old.IsValid() && current.IsValid() && reflect.DeepEqual(change.Before, change.After) {
+			continue
+		}
+		if empty(old) {
+			change.Before = nil
+		}
+		if empty(current) {
+			change.After = nil
+		}
+		change.Field = name
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// empty reports whether a field is missing or holds its zero value; empty
+// and nil slices and maps are alike.
+func empty(v reflect.Value) bool {
+	if !v.IsValid() || v.IsZero() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	. "reviewsch/internal/service/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateCampaign stores a new campaign with its budgets. A zero budget is
+// unlimited.
+func (s *Service) CreateCampaign(campaign Campaign) (*Campaign, error) {
+	if campaign.Name == "" {
+		return nil, fmt.Errorf("%w: empty campaign name", ErrInvalidRequest)
+	}
+	if campaign.Currency == "" {
+		campaign.Currency = DefaultCurrency
+	}
+	if _, err := LookupCurrency(campaign.Currency); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	budget, err := campaign.DiscountBudget.In(campaign.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: discount budget: %v", ErrCurrencyMismatch, err)
+	}
+	if budget.Amount < 0 || campaign.RedemptionBudget < 0 {
+		return nil, fmt.Errorf("%w: campaign budgets must not be negative", ErrInvalidRequest)
+	}
+
+	campaign.ID = uuid.NewString()
+	campaign.DiscountBudget = budget
+	campaign.DiscountSpent = Money{Currency: campaign.Currency}
+	campaign.Redemptions = 0
+	campaign.Paused = false
+
+	if err := s.campaigns.Save(campaign); err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// GetCampaign returns a campaign with its current spend.
+func (s *Service) GetCampaign(id string) (*Campaign, error) {
+	return s.campaigns.FindByID(id)
+}
+
+// checkCouponCampaign verifies that a coupon refers to a known campaign
+// that budgets in the coupon currency.
+func (s *Service) checkCouponCampaign(coupon Coupon) error {
+	if coupon.CampaignID == "" {
+		return nil
+	}
+	campaign, err := s.campaigns.FindByID(coupon.CampaignID)
+	if err != nil {
+		return err
+	}
+	if campaign.Currency != coupon.Currency {
+		return fmt.Errorf("%w: coupon in %s, campaign in %s",
+			ErrCurrencyMismatch, coupon.Currency, campaign.Currency)
+	}
+	return nil
+}
+
+// checkCampaign verifies that the coupon campaign, if any, is running and
+// can still fund the discount. Discounts in another currency than the
+// campaign budget are converted with the rate in effect at the given time.
+func (s *Service) checkCampaign(id string, discount Money, at time.Time) error {
+	if id == "" {
+		return nil
+	}
+	campaign, err := s.campaigns.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if discount, err = s.convert(discount, campaign.Currency, at); err != nil {
+		return err
+	}
+	if campaign.Paused {
+		return ErrCampaignPaused
+	}
+	if campaign.DiscountBudget.Amount > 0 && campaign.DiscountSpent.Add(discount).Amount > campaign.DiscountBudget.Amount {
+		return fmt.Errorf("%w: %s left, want %s",
+			ErrCampaignBudgetExhausted, campaign.DiscountBudget.Sub(campaign.DiscountSpent), discount)
+	}
+	return nil
+}
+
+// spend charges the discount to the campaign, if any, converted like in
+// checkCampaign.
+func (s *Service) spend(id string, discount Money, at time.Time) error {
+	if id == "" {
+		return nil
+	}
+	amount, err := s.inCampaignCurrency(id, discount, at)
+	if err != nil {
+		return err
+	}
+	return s.campaigns.Spend(id, amount)
+}
+
+// budgetActor is recorded for coupons paused because their campaign used
+// up its budget.
+var budgetActor = Actor{ID: "campaign-budget", Role: "system"}
+
+// pauseExhausted pauses the active coupons of every campaign whose budget
+// is used up, so their status shows that they can no longer be applied.
+// The pauses are recorded like any lifecycle change. Coupons stay paused
+// if a refund frees budget again; they are resumed with ResumeCoupon.
+func (s *Service) pauseExhausted(ids ...string) error {
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		campaign, err := s.campaigns.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if !campaign.Exhausted() {
+			continue
+		}
+		if err := s.pauseCampaignCoupons(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pauseCampaignCoupons pauses the active coupons of the campaign, reading
+// them page by page.
+func (s *Service) pauseCampaignCoupons(id string) error {
+	query := CouponQuery{CampaignID: id, Sort: SortByCode, Limit: MaxPageSize}
+	for {
+		coupons, err := s.repo.Query(query)
+		if err != nil {
+			return err
+		}
+		for _, coupon := range coupons {
+			if coupon.Status != CouponActive {
+				continue
+			}
+			// A coupon changed in the meantime is left as it is
+			_, err := s.transition(coupon.Code, budgetActor, CouponPaused, CouponActive)
+			if err != nil && !errors.Is(err, ErrInvalidTransition) {
+				return err
+			}
+		}
+		if len(coupons) < query.Limit {
+			return nil
+		}
+		position := CursorOf(coupons[len(coupons)-1])
+		query.After = &position
+	}
+}
+
+// refund gives a discount charged with spend at the same time back to the
+// campaign.
+func (s *Service) refund(id string, discount Money, at time.Time) error {
+	if id == "" {
+		return nil
+	}
+	amount, err := s.inCampaignCurrency(id, discount, at)
+	if err != nil {
+		return err
+	}
+	return s.campaigns.Refund(id, amount)
+}
+
+func (s *Service) inCampaignCurrency(id string, discount Money, at time.Time) (Money, error) {
+	campaign, err := s.campaigns.FindByID(id)
+	if err != nil {
+		return Money{}, err
+	}
+	return s.convert(discount, campaign.Currency, at)
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"fmt"
+	. "reviewsch/internal/service/entity"
+	"sort"
+)
+
+// validateDiscount checks that the discount settings of a coupon are usable
+// for its discount type.
+func validateDiscount(coupon Coupon) error {
+	switch coupon.Type {
+	case DiscountPercentage:
+		if coupon.Discount <= 0 || coupon.Discount > 100 {
+			return fmt.Errorf("%w: percentage discount must be between 1 and 100", ErrInvalidCoupon)
+		}
+	case DiscountFixedAmount:
+		if coupon.Discount <= 0 {
+			return fmt.Errorf("%w: fixed amount discount must be positive", ErrInvalidCoupon)
+		}
+		amount, err := MajorUnits(int64(coupon.Discount), coupon.Currency)
+		if err != nil || amount.Amount > MaxAmount {
+			return fmt.Errorf("%w: fixed amount discount must be at most %s", ErrInvalidCoupon, Money{Amount: MaxAmount, Currency: coupon.Currency})
+		}
+	case DiscountFreeShipping:
+	case DiscountBuyXGetY:
+		if coupon.BuyQuantity <= 0 || coupon.GetQuantity <= 0 {
+			return fmt.Errorf("%w: buy and get quantities must be positive", ErrInvalidCoupon)
+		}
+	default:
+		return fmt.Errorf("%w: unknown discount type %q", ErrInvalidCoupon, coupon.Type)
+	}
+	return nil
+}
+
+// validateAmounts checks the fixed amounts a coupon sets for other
+// currencies.
+func validateAmounts(coupon Coupon) error {
+	if len(coupon.Amounts) == 0 {
+		return nil
+	}
+	if coupon.Type != DiscountFixedAmount {
+		return fmt.Errorf("%w: amounts per currency are only allowed for fixed amount discounts", ErrInvalidCoupon)
+	}
+
+	for currency, amount := range coupon.Amounts {
+		if _, err := LookupCurrency(currency); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCoupon, err)
+		}
+		if currency == coupon.Currency {
+			return fmt.Errorf("%w: amount for %s duplicates the discount", ErrInvalidCoupon, currency)
+		}
+		if amount.Currency != currency {
+			return fmt.Errorf("%w: amount for %s given in %s", ErrInvalidCoupon, currency, amount.Currency)
+		}
+		if amount.Amount <= 0 {
+			return fmt.Errorf("%w: amount for %s must be positive", ErrInvalidCoupon, currency)
+		}
+		if amount.Amount > MaxAmount {
+			return fmt.Errorf("%w: amount for %s must be at most %s", ErrInvalidCoupon, currency, Money{Amount: MaxAmount, Currency: currency})
+		}
+	}
+	return nil
+}
+
+// normalizeBasket derives the basket value and quantity from its line items
+// and clears any discount allocated by a previous application. Baskets
+// without line items keep the value and quantity sent by the client. Every
+// amount ends up in the basket currency, which defaults to DefaultCurrency.
+func normalizeBasket(basket *Basket) error {
+	if basket.Currency == "" {
+		basket.Currency = DefaultCurrency
+	}
+	if _, err := LookupCurrency(basket.Currency); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+
+	zero := Money{Currency: basket.Currency}
+	var err error
+	if basket.Value, err = inBasketCurrency(basket.Value, basket.Currency); err != nil {
+		return err
+	}
+	if basket.ShippingCost, err = inBasketCurrency(basket.ShippingCost, basket.Currency); err != nil {
+		return err
+	}
+	if basket.Value.Amount < 0 || basket.ShippingCost.Amount < 0 || basket.Quantity < 0 {
+		return fmt.Errorf("%w: basket value, shipping cost and quantity must not be negative", ErrInvalidRequest)
+	}
+	maximum := Money{Amount: MaxAmount, Currency: basket.Currency}
+	if basket.Value.Amount > MaxAmount || basket.ShippingCost.Amount > MaxAmount {
+		return fmt.Errorf("%w: basket value and shipping cost must be at most %s", ErrInvalidRequest, maximum)
+	}
+	basket.OriginalValue, basket.DiscountAmount, basket.FinalValue = zero, zero, zero
+
+	if len(basket.Items) == 0 {
+		return nil
+	}
+
+	items := make([]LineItem, len(basket.Items))
+	value := zero
+	var quantity int
+	for i, item := range basket.Items {
+		if item.Quantity <= 0 || item.UnitPrice.Amount < 0 {
+			return fmt.Errorf("%w: invalid basket item %q", ErrInvalidRequest, item.SKU)
+		}
+		if item.UnitPrice, err = inBasketCurrency(item.UnitPrice, basket.Currency); err != nil {
+			return err
+		}
+		if item.UnitPrice.Amount > 0 && int64(item.Quantity) > (MaxAmount-value.Amount)/item.UnitPrice.Amount {
+			return fmt.Errorf("%w: basket value must be at most %s", ErrInvalidRequest, maximum)
+		}
+		item.Discount = zero
+		items[i] = item
+		value = value.Add(item.Total())
+		quantity += item.Quantity
+	}
+
+	basket.Items = items
+	basket.Value = value
+	basket.Quantity = quantity
+	return nil
+}
+
+// inBasketCurrency checks that an amount sent with the basket is in the
+// basket currency.
+func inBasketCurrency(amount Money, currency string) (Money, error) {
+	amount, err := amount.In(currency)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %v", ErrCurrencyMismatch, err)
+	}
+	return amount, nil
+}
+
+// line is a basket line the coupon discount can be allocated to. Its total
+// is the value left after the discounts already allocated to it. Baskets
+// without line items are treated as a single line with index -1.
+type line struct {
+	index     int
+	unitPrice Money
+	quantity  int
+	total     Money
+}
+
+// eligibleLines returns the basket lines the coupon applies to.
+func eligibleLines(coupon *Coupon, basket *Basket) []line {
+	if len(basket.Items) == 0 {
+		if coupon.Targeted() {
+			return nil
+		}
+		whole := line{index: -1, unitPrice: Money{Currency: basket.Currency}, quantity: basket.Quantity, total: basket.Value}
+		if basket.Quantity > 0 {
+			whole.unitPrice = basket.Value.MulDiv(1, int64(basket.Quantity))
+		}
+		return []line{whole}
+	}
+
+	var lines []line
+	for i, item := range basket.Items {
+		if coupon.Eligible(item) {
+			lines = append(lines, line{index: i, unitPrice: item.UnitPrice, quantity: item.Quantity, total: item.Total().Sub(item.Discount)})
+		}
+	}
+	return lines
+}
+
+// applyDiscount calculates how much the coupon takes off the basket and
+// adds the discount to the eligible line items. The coupon is calculated on
+// the value left on each line, so a line is never discounted below zero.
+func applyDiscount(coupon *Coupon, basket *Basket) (Money, error) {
+	lines := eligibleLines(coupon, basket)
+	if len(lines) == 0 {
+		return Money{}, ErrNoEligibleItems
+	}
+
+	if coupon.Type == DiscountFreeShipping {
+		return basket.ShippingCost, nil
+	}
+
+	var allocated []Money
+	switch coupon.Type {
+	case DiscountFixedAmount:
+		amount, ok := coupon.AmountIn(basket.Currency)
+		if !ok {
+			var err error
+			if amount, err = MajorUnits(int64(coupon.Discount), basket.Currency); err != nil {
+				return Money{}, err
+			}
+		}
+		allocated = allocateFixed(lines, amount)
+	case DiscountBuyXGetY:
+		allocated = allocateFreeUnits(lines, coupon.BuyQuantity, coupon.GetQuantity)
+	default:
+		allocated = make([]Money, len(lines))
+		for i, l := range lines {
+			allocated[i] = l.total.MulDiv(int64(coupon.Discount), 100)
+		}
+	}
+
+	discount := Money{Currency: basket.Currency}
+	for i, l := range lines {
+		allocated[i] = allocated[i].Min(l.total)
+		if l.index >= 0 {
+			item := &basket.Items[l.index]
+			item.Discount = item.Discount.Add(allocated[i])
+		}
+		discount = discount.Add(allocated[i])
+	}
+
+	return discount, nil
+}
+
+// allocateFixed spreads a fixed amount over the lines in proportion to
+// their value. The last line absorbs the rounding difference.
+func allocateFixed(lines []line, amount Money) []Money {
+	eligible := Money{Currency: amount.Currency}
+	for _, l := range lines {
+		eligible = eligible.Add(l.total)
+	}
+	amount = amount.Min(eligible)
+
+	allocated := make([]Money, len(lines))
+	for i := range allocated {
+		allocated[i] = Money{Currency: amount.Currency}
+	}
+	if eligible.IsZero() {
+		return allocated
+	}
+
+	spent := Money{Currency: amount.Currency}
+	for i, l := range lines {
+		if i == len(lines)-1 {
+			allocated[i] = amount.Sub(spent)
+			break
+		}
+		allocated[i] = amount.MulDiv(l.total.Amount, eligible.Amount)
+		spent = spent.Add(allocated[i])
+	}
+	return allocated
+}
+
+// allocateFreeUnits makes get units free for every buy+get eligible units,
+// starting with the cheapest ones.
+func allocateFreeUnits(lines []line, buy, get int) []Money {
+	var units int
+	for _, l := range lines {
+		units += l.quantity
+	}
+	free := units / (buy + get) * get
+
+	order := make([]int, len(lines))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return lines[order[a]].unitPrice.Amount < lines[order[b]].unitPrice.Amount
+	})
+
+	allocated := make([]Money, len(lines))
+	for i, l := range lines {
+		allocated[i] = Money{Currency: l.total.Currency}
+	}
+	for _, i := range order {
+		if free == 0 {
+			break
+		}
+		n := min(free, lines[i].quantity)
+		allocated[i] = lines[i].unitPrice.Mul(n)
+		free -= n
+	}
+	return allocated
+}
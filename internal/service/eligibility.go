@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	. "reviewsch/internal/service/entity"
+	"reviewsch/internal/service/rule"
+	"strconv"
+	"strings"
+)
+
+// ruleSchema declares the variables coupon eligibility rules can read.
+// customer.new and customer.redemptions come from the redemption ledger and
+// need a known customer.
+var ruleSchema = rule.Schema{
+	"customer.id":          rule.String,
+	"customer.role":        rule.String,
+	"customer.new":         rule.Bool,
+	"customer.redemptions": rule.Number,
+	"basket.value":         rule.Number,
+	"basket.shipping":      rule.Number,
+	"basket.quantity":      rule.Number,
+	"basket.skus":          rule.List,
+	"basket.categories":    rule.List,
+}
+
+// compileRule checks a coupon eligibility rule.
+func compileRule(src string) (*rule.Rule, error) {
+	r, err := rule.Compile(src, ruleSchema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: rule: %v", ErrInvalidCoupon, err)
+	}
+	return r, nil
+}
+
+// checkRule evaluates the coupon eligibility rule, if any, against the
+// normalized basket and the customer. It describes the values the rule
+// read, e.g. `basket.value=120 customer.role="vip"`.
+func (s *Service) checkRule(coupon *Coupon, basket *Basket, customer Customer) (string, error) {
+	if coupon.Rule == "" {
+		return "", nil
+	}
+	r, err := compileRule(coupon.Rule)
+	if err != nil {
+		return "", err
+	}
+
+	skus := make([]string, 0, len(basket.Items))
+	categories := make([]string, 0, len(basket.Items))
+	for _, item := range basket.Items {
+		skus = append(skus, item.SKU)
+		categories = append(categories, item.Category)
+	}
+
+	env := rule.Env{
+		"customer.id":       customer.ID,
+		"customer.role":     customer.Role,
+		"basket.value":      basket.Value.Float(),
+		"basket.shipping":   basket.ShippingCost.Float(),
+		"basket.quantity":   float64(basket.Quantity),
+		"basket.skus":       skus,
+		"basket.categories": categories,
+	}
+
+	if r.Uses("customer.new") || r.Uses("customer.redemptions") {
+		if customer.ID == "" {
+			return "", ErrCustomerRequired
+		}
+		count, err := s.ledger.CustomerRedemptions(customer.ID)
+		if err != nil {
+			return "", err
+		}
+		env["customer.new"] = count == 0
+		env["customer.redemptions"] = float64(count)
+	}
+
+	values := make([]string, 0, len(r.Variables()))
+	for _, name := range r.Variables() {
+		values = append(values, fmt.Sprintf("%s=%s", name, formatValue(env[name])))
+	}
+	read := strings.Join(values, " ")
+
+	ok, err := r.Eval(env)
+	if err != nil {
+		return read, err
+	}
+	if !ok {
+		return read, ErrRuleNotSatisfied
+	}
+	return read, nil
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string, []string:
+		return fmt.Sprintf("%q", v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
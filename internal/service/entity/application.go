@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+// Application is the result of applying several coupons to a basket
+// @Description Basket with the coupon combination that was applied and the codes that were dropped
+type Application struct {
+	Basket  Basket          `json:"basket"`
+	Applied []AppliedCoupon `json:"applied"`
+	Dropped []DroppedCoupon `json:"dropped"`
+}
+
+// AppliedCoupon is a coupon that is part of the applied combination
+type AppliedCoupon struct {
+	Code           string        `json:"code" example:"SUMMER2024"`
+	VersionID      string        `json:"versionId,omitempty" example:"6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60-v3"`
+	DiscountAmount Money         `json:"discountAmount" swaggertype:"number" example:"10.05"`
+	ExchangeRate   *ExchangeRate `json:"exchangeRate,omitempty"`
+}
+
+// DroppedCoupon is a submitted code that was left out of the combination.
+// Reason is the stable code of Err, filled in by the API layer.
+type DroppedCoupon struct {
+	Code   string `json:"code" example:"WINTER2024"`
+	Reason string `json:"reason" example:"coupon_not_combinable"`
+	Detail string `json:"detail" example:"coupon not combinable: coupon SUMMER2024 cannot be combined with other coupons"`
+	Err    error  `json:"-"`
+}
+
+// ApplicableCoupon is a coupon a basket qualifies for
+// @Description Coupon the basket qualifies for, with the discount applying it on its own would grant
+type ApplicableCoupon struct {
+	Code           string        `json:"code" example:"SUMMER2024"`
+	Type           DiscountType  `json:"type" example:"percentage"`
+	Discount       int           `json:"discount" example:"10"`
+	DiscountAmount Money         `json:"discountAmount" swaggertype:"number" example:"10.05"`
+	FinalValue     Money         `json:"finalValue" swaggertype:"number" example:"95.44"`
+	Stackable      bool          `json:"stackable" example:"false"`
+	ExpiresAt      *time.Time    `json:"expiresAt,omitempty" example:"2024-09-01T00:00:00Z"`
+	ExchangeRate   *ExchangeRate `json:"exchangeRate,omitempty"`
+}
+
+// AssignedCoupon is a personal coupon its customer can still use
+// @Description Personal coupon assigned to the customer that has not been used and has not expired
+type AssignedCoupon struct {
+	Code           string       `json:"code" example:"WELCOME-BACK-7Q2X"`
+	Type           DiscountType `json:"type" example:"percentage"`
+	Discount       int          `json:"discount" example:"15"`
+	Currency       string       `json:"currency" example:"EUR"`
+	MinBasketValue Money        `json:"minBasketValue" swaggertype:"number" example:"50.00"`
+	Stackable      bool         `json:"stackable" example:"false"`
+	StartsAt       *time.Time   `json:"startsAt,omitempty" example:"2024-06-01T00:00:00Z"`
+	ExpiresAt      *time.Time   `json:"expiresAt,omitempty" example:"2024-09-01T00:00:00Z"`
+}
+
+// Recommendation is the best coupon combination for a basket among the
+// codes a customer holds
+// @Description Codes giving the largest discount, the basket they produce and the other valid combinations ranked by savings
+type Recommendation struct {
+	Codes        []string        `json:"codes" example:"TEN,FREESHIP"`
+	Savings      Money           `json:"savings" swaggertype:"number" example:"14.99"`
+	Basket       Basket          `json:"basket"`
+	Alternatives []CouponSet     `json:"alternatives"`
+	Dropped      []DroppedCoupon `json:"dropped"`
+}
+
+// CouponSet is a combination of codes that can be applied together
+type CouponSet struct {
+	Codes      []string `json:"codes" example:"TEN"`
+	Savings    Money    `json:"savings" swaggertype:"number" example:"10.00"`
+	FinalValue Money    `json:"finalValue" swaggertype:"number" example:"94.99"`
+}
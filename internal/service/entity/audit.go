@@ -0,0 +1,76 @@
+package entity
+
+import "time"
+
+// Actor identifies who changes a coupon, as taken from the JWT claims, and
+// the request that carried the change
+type Actor struct {
+	ID        string
+	Role      string
+	RequestID string
+}
+
+// AuditAction is the kind of change an audit entry records
+type AuditAction string
+
+const (
+	// AuditCreate records a new coupon, created or generated
+	AuditCreate AuditAction = "create"
+	// AuditUpdate records changed coupon terms
+	AuditUpdate AuditAction = "update"
+	// AuditStatus records a lifecycle status change
+	AuditStatus AuditAction = "status"
+	// AuditDelete records a deleted coupon
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntry records one change of a coupon. Entries are never changed or
+// removed.
+// @Description Who changed a coupon, when, in which request and which fields changed
+type AuditEntry struct {
+	ID        string        `json:"id" example:"6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60"`
+	Code      string        `json:"code" example:"SUMMER2024"`
+	Action    AuditAction   `json:"action" example:"update"`
+	ActorID   string        `json:"actorId" example:"123"`
+	ActorRole string        `json:"actorRole" example:"admin"`
+	RequestID string        `json:"requestId,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	At        time.Time     `json:"at" example:"2024-06-01T12:00:00Z"`
+	Version   int           `json:"version" example:"3"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// FieldChange is a coupon field that differs before and after a change.
+// The value before a create and after a delete is empty.
+type FieldChange struct {
+	Field  string `json:"field" example:"Discount"`
+	Before any    `json:"before,omitempty" swaggertype:"string" example:"10"`
+	After  any    `json:"after,omitempty" swaggertype:"string" example:"15"`
+}
+
+// AuditQuery selects audit entries. Empty fields do not filter; From is
+// inclusive and To exclusive.
+type AuditQuery struct {
+	Code    string
+	ActorID string
+	Action  AuditAction
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
+
+// Matches reports whether the entry is selected by the query
+func (q AuditQuery) Matches(entry AuditEntry) bool {
+	if q.Code != "" && entry.Code != q.Code {
+		return false
+	}
+	if q.ActorID != "" && entry.ActorID != q.ActorID {
+		return false
+	}
+	if q.Action != "" && entry.Action != q.Action {
+		return false
+	}
+	if !q.From.IsZero() && entry.At.Before(q.From) {
+		return false
+	}
+	return q.To.IsZero() || entry.At.Before(q.To)
+}
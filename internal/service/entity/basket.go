@@ -1,14 +1,44 @@
 package entity
 
 import (
+	"time"
+
 	_ "github.com/gin-gonic/gin"
 )
 
 // Basket represents a shopping basket
 // @Description Shopping basket with coupon application details
 type Basket struct {
-	Value                 float64 `json:"value" example:"100.50"`
-	AppliedDiscount       int     `json:"appliedDiscount" example:"10"`
-	ApplicationSuccessful bool    `json:"applicationSuccessful" example:"true"`
-	CouponCode            string  `json:"couponCode" example:"SUMMER2024"`
+	Currency              string     `json:"currency" example:"EUR"`
+	Value                 Money      `json:"value" swaggertype:"number" example:"100.50"`
+	ShippingCost          Money      `json:"shippingCost" swaggertype:"number" example:"4.99"`
+	Quantity              int        `json:"quantity" example:"3"`
+	Items                 []LineItem `json:"items,omitempty"`
+	AppliedDiscount       int        `json:"appliedDiscount" example:"10"`
+	ApplicationSuccessful bool       `json:"applicationSuccessful" example:"true"`
+	CouponCode            string     `json:"couponCode" example:"SUMMER2024"`
+	CouponVersionID       string     `json:"couponVersionId,omitempty" example:"6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60-v3"`
+	OriginalValue         Money      `json:"originalValue" swaggertype:"number" example:"100.50"`
+	DiscountAmount        Money      `json:"discountAmount" swaggertype:"number" example:"10.05"`
+	FinalValue            Money      `json:"finalValue" swaggertype:"number" example:"95.44"`
+	ReservationID         string     `json:"reservationId,omitempty" example:"6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60"`
+	ReservedUntil         *time.Time `json:"reservedUntil,omitempty" example:"2024-06-01T12:15:00Z"`
+	// ExchangeRate is the rate used to convert the coupon amounts into the
+	// basket currency, if they had to be converted
+	ExchangeRate *ExchangeRate `json:"exchangeRate,omitempty"`
+}
+
+// LineItem represents a product line in a basket
+// @Description Basket line with the share of the coupon discount allocated to it
+type LineItem struct {
+	SKU       string `json:"sku" example:"SHOE-42-BLK"`
+	Category  string `json:"category" example:"shoes"`
+	UnitPrice Money  `json:"unitPrice" swaggertype:"number" example:"33.50"`
+	Quantity  int    `json:"quantity" example:"3"`
+	Discount  Money  `json:"discount" swaggertype:"number" example:"10.05"`
+}
+
+// Total returns the undiscounted value of the line
+func (i LineItem) Total() Money {
+	return i.UnitPrice.Mul(i.Quantity)
 }
@@ -0,0 +1,21 @@
+package entity
+
+// Campaign groups coupons under a shared budget
+// @Description Promotion owning many coupons with a discount and redemption budget
+type Campaign struct {
+	ID               string `json:"id" example:"3b7e9c2a-1f4d-4c8e-9a6b-2d5f8e1c7a90"`
+	Name             string `json:"name" example:"Summer sale"`
+	Currency         string `json:"currency" example:"EUR"`
+	DiscountBudget   Money  `json:"discountBudget" swaggertype:"number" example:"5000"`
+	RedemptionBudget int    `json:"redemptionBudget" example:"1000"`
+	DiscountSpent    Money  `json:"discountSpent" swaggertype:"number" example:"1250.40"`
+	Redemptions      int    `json:"redemptions" example:"231"`
+	Paused           bool   `json:"paused" example:"false"`
+}
+
+// Exhausted reports whether either budget has been used up. A zero budget
+// is unlimited.
+func (c Campaign) Exhausted() bool {
+	return (c.DiscountBudget.Amount > 0 && c.DiscountSpent.Amount >= c.DiscountBudget.Amount) ||
+		(c.RedemptionBudget > 0 && c.Redemptions >= c.RedemptionBudget)
+}
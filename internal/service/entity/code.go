@@ -0,0 +1,10 @@
+package entity
+
+// CodeSpec describes how generated coupon codes look
+type CodeSpec struct {
+	Count          int
+	Prefix         string
+	Length         int
+	Alphabet       string
+	AvoidAmbiguous bool
+}
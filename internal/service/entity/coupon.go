@@ -1,10 +1,116 @@
 package entity
 
+import (
+	"slices"
+	"time"
+)
+
+// DiscountType describes how a coupon discount is calculated
+type DiscountType string
+
+const (
+	// DiscountPercentage takes Discount percent off the basket value
+	DiscountPercentage DiscountType = "percentage"
+	// DiscountFixedAmount takes Discount currency units off the basket value
+	DiscountFixedAmount DiscountType = "fixed_amount"
+	// DiscountFreeShipping waives the basket shipping cost
+	DiscountFreeShipping DiscountType = "free_shipping"
+	// DiscountBuyXGetY makes GetQuantity items free for every BuyQuantity bought
+	DiscountBuyXGetY DiscountType = "buy_x_get_y"
+)
+
+// CouponStatus is the lifecycle state of a coupon
+type CouponStatus string
+
+const (
+	// CouponDraft is being prepared and cannot be applied yet
+	CouponDraft CouponStatus = "draft"
+	// CouponActive can be applied
+	CouponActive CouponStatus = "active"
+	// CouponPaused is temporarily switched off and can be resumed
+	CouponPaused CouponStatus = "paused"
+	// CouponArchived is switched off for good; its history is kept
+	CouponArchived CouponStatus = "archived"
+)
+
 // Coupon represents a discount coupon
 // @Description Discount coupon
 type Coupon struct {
 	ID             string
 	Code           string
+	CampaignID     string
+	Status         CouponStatus
+	Version        int
+	Type           DiscountType
 	Discount       int
-	MinBasketValue float64
+	Currency       string
+	MinBasketValue Money `swaggertype:"number"`
+	// Amounts are fixed amount discounts by currency for other currencies
+	// than the coupon currency. Without one the discount is converted with
+	// the exchange rate in effect when the coupon is applied.
+	Amounts        map[string]Money `swaggertype:"object,number"`
+	BuyQuantity    int
+	GetQuantity    int
+	StartsAt       time.Time
+	ExpiresAt      time.Time
+	MaxRedemptions int
+	MaxPerCustomer int
+	// Targeting restricts the discount to matching basket lines. When both
+	// include lists are empty every line that is not excluded is eligible.
+	IncludeSKUs       []string
+	ExcludeSKUs       []string
+	IncludeCategories []string
+	ExcludeCategories []string
+	// Stacking rules decide which coupons can be applied together. Only
+	// stackable coupons combine, at most one per exclusivity group, and
+	// coupons with a higher priority are applied first.
+	Stackable        bool
+	ExclusivityGroup string
+	Priority         int
+	// Rule is an optional eligibility expression evaluated against the
+	// basket and the customer, see package rule.
+	Rule string
+	// CustomerIDs makes the coupon personal: only the listed customers can
+	// apply it. A coupon without any is public.
+	CustomerIDs []string
+}
+
+// AmountIn returns the fixed amount set for the currency, if any
+func (c Coupon) AmountIn(currency string) (Money, bool) {
+	amount, ok := c.Amounts[currency]
+	return amount, ok
+}
+
+// InWindow reports whether the time is within the start and expiry time of
+// the coupon
+func (c Coupon) InWindow(at time.Time) bool {
+	return (c.StartsAt.IsZero() || !at.Before(c.StartsAt)) && (c.ExpiresAt.IsZero() || at.Before(c.ExpiresAt))
+}
+
+// Personal reports whether the coupon is assigned to specific customers
+func (c Coupon) Personal() bool {
+	return len(c.CustomerIDs) > 0
+}
+
+// AssignedTo reports whether the customer may apply the coupon, which is
+// anyone for a public coupon
+func (c Coupon) AssignedTo(customerID string) bool {
+	return !c.Personal() || slices.Contains(c.CustomerIDs, customerID)
+}
+
+// Targeted reports whether the coupon only applies to some basket lines
+func (c Coupon) Targeted() bool {
+	return len(c.IncludeSKUs) > 0 || len(c.ExcludeSKUs) > 0 ||
+		len(c.IncludeCategories) > 0 || len(c.ExcludeCategories) > 0
+}
+
+// Eligible reports whether the coupon discount applies to the line
+func (c Coupon) Eligible(item LineItem) bool {
+	if slices.Contains(c.ExcludeSKUs, item.SKU) || slices.Contains(c.ExcludeCategories, item.Category) {
+		return false
+	}
+	if len(c.IncludeSKUs) == 0 && len(c.IncludeCategories) == 0 {
+		return true
+	}
+	return slices.Contains(c.IncludeSKUs, item.SKU) || slices.Contains(c.IncludeCategories, item.Category)
 }
@@ -0,0 +1,7 @@
+package entity
+
+// Customer identifies who applies a coupon, as taken from the JWT claims
+type Customer struct {
+	ID   string
+	Role string
+}
@@ -0,0 +1,45 @@
+package entity
+
+// Names of the checks a coupon goes through when it is applied, in order.
+// Checks lists them in that order.
+const (
+	CheckExists     = "exists"
+	CheckActive     = "active"
+	CheckTimeWindow = "time_window"
+	CheckCustomer   = "customer"
+	CheckBasket     = "basket"
+	CheckCurrency   = "currency"
+	CheckMinBasket  = "min_basket"
+	CheckLimits     = "limits"
+	CheckRule       = "rule"
+	CheckDiscount   = "discount"
+	CheckCampaign   = "campaign"
+)
+
+// Checks are the names of the checks, in the order they run
+var Checks = []string{
+	CheckExists, CheckActive, CheckTimeWindow, CheckCustomer, CheckBasket, CheckCurrency,
+	CheckMinBasket, CheckLimits, CheckRule, CheckDiscount, CheckCampaign,
+}
+
+// EligibilityCheck is one step of applying a coupon, with the values it
+// compared. A skipped check did not run because an earlier one failed.
+type EligibilityCheck struct {
+	Name     string `json:"name" example:"min_basket"`
+	Passed   bool   `json:"passed" example:"false"`
+	Skipped  bool   `json:"skipped,omitempty" example:"false"`
+	Actual   string `json:"actual,omitempty" example:"40.00 EUR"`
+	Expected string `json:"expected,omitempty" example:">= 50.00 EUR"`
+	Detail   string `json:"detail,omitempty" example:"basket value below coupon minimum: got 40.00, want minimum 50.00"`
+}
+
+// Eligibility is the outcome of a dry run of a coupon against a basket
+// @Description Every check applying the coupon goes through, in order; the checks after the first one that failed are skipped
+type Eligibility struct {
+	Code     string             `json:"code" example:"SUMMER2024"`
+	Eligible bool               `json:"eligible"`
+	Reason   string             `json:"reason,omitempty" example:"below_min_basket_value"`
+	Checks   []EligibilityCheck `json:"checks"`
+	Basket   *Basket            `json:"basket,omitempty"`
+	Err      error              `json:"-"`
+}
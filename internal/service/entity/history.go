@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// CouponVersion is a coupon as it was stored by one change. Versions are
+// never changed; a coupon deleted and created again with the same code
+// starts over at version 1 under a new ID. Deleting a coupon records a
+// last version marked Deleted that keeps the terms it had.
+// @Description Coupon terms as they were from recordedAt until the next version
+type CouponVersion struct {
+	ID         string    `json:"id" example:"6f1c2a9e-8d1b-4f4e-9a57-3c1b2d4e5f60-v3"`
+	Code       string    `json:"code" example:"SUMMER2024"`
+	Version    int       `json:"version" example:"3"`
+	RecordedAt time.Time `json:"recordedAt" example:"2024-06-01T12:00:00Z"`
+	Deleted    bool      `json:"deleted,omitempty" example:"false"`
+	Coupon     Coupon    `json:"coupon"`
+}
+
+// VersionID names the version of the coupon, unique across coupons that
+// reuse a code. Coupons without an ID were never versioned and have none.
+func (c Coupon) VersionID() string {
+	if c.ID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s-v%d", c.ID, c.Version)
+}
+
+// CouponDiff lists the fields changed between two versions of a coupon
+// @Description Fields whose value differs between version from and version to
+type CouponDiff struct {
+	Code    string        `json:"code" example:"SUMMER2024"`
+	From    int           `json:"from" example:"2"`
+	To      int           `json:"to" example:"3"`
+	Changes []FieldChange `json:"changes"`
+}
@@ -0,0 +1,337 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RoundingMode decides how an amount that falls between two minor units is
+// rounded
+type RoundingMode string
+
+const (
+	// RoundHalfUp rounds ties away from zero
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds ties to the nearest even minor unit
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundDown truncates towards zero
+	RoundDown RoundingMode = "down"
+	// RoundUp rounds away from zero
+	RoundUp RoundingMode = "up"
+)
+
+// Currency describes how amounts of an ISO 4217 currency are stored and
+// rounded
+type Currency struct {
+	Code       string
+	MinorUnits int
+	Rounding   RoundingMode
+}
+
+// DefaultCurrency is used for baskets, coupons and campaigns that do not
+// name a currency
+const DefaultCurrency = "EUR"
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var (
+	currenciesMu sync.RWMutex
+	currencies   = map[string]Currency{
+		"EUR": {Code: "EUR", MinorUnits: 2, Rounding: RoundHalfUp},
+		"USD": {Code: "USD", MinorUnits: 2, Rounding: RoundHalfUp},
+		"GBP": {Code: "GBP", MinorUnits: 2, Rounding: RoundHalfUp},
+		"CHF": {Code: "CHF", MinorUnits: 2, Rounding: RoundHalfUp},
+		"PLN": {Code: "PLN", MinorUnits: 2, Rounding: RoundHalfUp},
+		"SEK": {Code: "SEK", MinorUnits: 2, Rounding: RoundHalfUp},
+		"DKK": {Code: "DKK", MinorUnits: 2, Rounding: RoundHalfUp},
+		"NOK": {Code: "NOK", MinorUnits: 2, Rounding: RoundHalfUp},
+		"TRY": {Code: "TRY", MinorUnits: 2, Rounding: RoundHalfUp},
+		"JPY": {Code: "JPY", MinorUnits: 0, Rounding: RoundHalfUp},
+		"KWD": {Code: "KWD", MinorUnits: 3, Rounding: RoundHalfUp},
+	}
+)
+
+// LookupCurrency returns the settings of a known currency
+func LookupCurrency(code string) (Currency, error) {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+
+	currency, ok := currencies[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("unknown currency %q", code)
+	}
+	return currency, nil
+}
+
+// RegisterCurrency adds a currency or replaces the settings of a known one
+func RegisterCurrency(currency Currency) error {
+	if !currencyCode.MatchString(currency.Code) {
+		return fmt.Errorf("invalid currency code %q", currency.Code)
+	}
+	if currency.MinorUnits < 0 || currency.MinorUnits > 4 {
+		return fmt.Errorf("currency %s: minor units must be between 0 and 4", currency.Code)
+	}
+	switch currency.Rounding {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundUp:
+	default:
+		return fmt.Errorf("currency %s: unknown rounding mode %q", currency.Code, currency.Rounding)
+	}
+
+	currenciesMu.Lock()
+	defer currenciesMu.Unlock()
+	currencies[currency.Code] = currency
+	return nil
+}
+
+// SetRounding changes the rounding mode of a known currency
+func SetRounding(code string, mode RoundingMode) error {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return err
+	}
+	currency.Rounding = mode
+	return RegisterCurrency(currency)
+}
+
+// MaxAmount is the largest amount in minor units accepted in baskets and
+// coupon terms. It leaves enough headroom that adding up and multiplying
+// accepted amounts stays within int64.
+const MaxAmount int64 = 1_000_000_000_000_000
+
+// Money is an exact amount in the minor units of a currency, e.g. cents.
+// The zero value is a zero amount that takes the currency of whatever it is
+// combined with.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// MajorUnits returns units whole units of the currency, e.g. 5 EUR
+func MajorUnits(units int64, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	scale := pow10(c.MinorUnits)
+	if units > math.MaxInt64/scale || units < math.MinInt64/scale {
+		return Money{}, fmt.Errorf("amount out of range")
+	}
+	return Money{Amount: units * scale, Currency: currency}, nil
+}
+
+// ParseMoney reads a decimal amount such as "100.50" or "1e2". Digits
+// beyond the minor units of the currency are rounded with its rounding
+// mode.
+func ParseMoney(amount, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+	value.Mul(value, new(big.Rat).SetInt64(pow10(c.MinorUnits)))
+
+	minor, err := round(value.Num(), value.Denom(), c.Rounding)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// In returns the amount in the currency, adopting it for a zero value
+// without one. It fails if the amount is already in another currency.
+func (m Money) In(currency string) (Money, error) {
+	if m.Currency == "" {
+		m.Currency = currency
+		return m, nil
+	}
+	if m.Currency != currency {
+		return Money{}, fmt.Errorf("amount in %s, want %s", m.Currency, currency)
+	}
+	return m, nil
+}
+
+// Add returns m+o. Both amounts must be in the same currency. It panics if
+// the sum overflows; amounts are bounded by MaxAmount before they get here.
+func (m Money) Add(o Money) Money {
+	amount := m.Amount + o.Amount
+	if (o.Amount > 0 && amount < m.Amount) || (o.Amount < 0 && amount > m.Amount) {
+		panic("money: amount out of range")
+	}
+	return Money{Amount: amount, Currency: m.currencyWith(o)}
+}
+
+// Sub returns m-o. Both amounts must be in the same currency. It panics if
+// the difference overflows.
+func (m Money) Sub(o Money) Money {
+	amount := m.Amount - o.Amount
+	if (o.Amount > 0 && amount > m.Amount) || (o.Amount < 0 && amount < m.Amount) {
+		panic("money: amount out of range")
+	}
+	return Money{Amount: amount, Currency: m.currencyWith(o)}
+}
+
+// Mul returns m*n. It panics if the product overflows.
+func (m Money) Mul(n int) Money {
+	amount, ok := mul(m.Amount, int64(n))
+	if !ok {
+		panic("money: amount out of range")
+	}
+	return Money{Amount: amount, Currency: m.Currency}
+}
+
+// MulDiv returns m*num/den rounded with the rounding mode of the currency.
+// It is used for percentages and proportional shares.
+func (m Money) MulDiv(num, den int64) Money {
+	mode := RoundHalfUp
+	if c, err := LookupCurrency(m.Currency); err == nil {
+		mode = c.Rounding
+	}
+
+	n := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	amount, err := round(n, big.NewInt(den), mode)
+	if err != nil {
+		panic(fmt.Sprintf("money: %v", err))
+	}
+	return Money{Amount: amount, Currency: m.Currency}
+}
+
+// Convert returns the amount in another currency. The ratio is the price of
+// one major unit of m's currency in the target currency; the result is
+// rounded with the rounding mode of the target currency.
+func (m Money) Convert(ratio *big.Rat, currency string) (Money, error) {
+	target, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value := new(big.Rat).SetFrac64(m.Amount, pow10(m.minorUnits()))
+	value.Mul(value, ratio)
+	value.Mul(value, new(big.Rat).SetInt64(pow10(target.MinorUnits)))
+
+	amount, err := round(value.Num(), value.Denom(), target.Rounding)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Min returns the smaller of m and o
+func (m Money) Min(o Money) Money {
+	if o.Amount < m.Amount {
+		return Money{Amount: o.Amount, Currency: m.currencyWith(o)}
+	}
+	return Money{Amount: m.Amount, Currency: m.currencyWith(o)}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Float returns an approximation of the amount in major units, for display
+// and rule evaluation only
+func (m Money) Float() float64 {
+	value, _ := new(big.Rat).SetFrac64(m.Amount, pow10(m.minorUnits())).Float64()
+	return value
+}
+
+// String formats the amount in major units with all minor digits, e.g.
+// "100.50"
+func (m Money) String() string {
+	units := m.minorUnits()
+	if units == 0 {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	scale := pow10(units)
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, units, amount%scale)
+}
+
+// MarshalJSON writes the amount as a decimal number in major units, so
+// clients keep receiving the same numbers as before amounts became exact.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m Money) currencyWith(o Money) string {
+	if m.Currency == "" {
+		return o.Currency
+	}
+	return m.Currency
+}
+
+func (m Money) minorUnits() int {
+	if c, err := LookupCurrency(m.Currency); err == nil {
+		return c.MinorUnits
+	}
+	return 2
+}
+
+// round divides num by den and rounds the quotient with the mode.
+func round(num, den *big.Int, mode RoundingMode) (int64, error) {
+	if den.Sign() == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	if den.Sign() < 0 {
+		num, den = new(big.Int).Neg(num), new(big.Int).Neg(den)
+	}
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		away := false
+		switch mode {
+		case RoundUp:
+			away = true
+		case RoundHalfUp, RoundHalfEven:
+			twice := new(big.Int).Abs(rem)
+			twice.Lsh(twice, 1)
+			switch twice.Cmp(den) {
+			case 1:
+				away = true
+			case 0:
+				away = mode == RoundHalfUp || quo.Bit(0) == 1
+			}
+		}
+		if away {
+			quo.Add(quo, big.NewInt(int64(num.Sign())))
+		}
+	}
+
+	if !quo.IsInt64() {
+		return 0, fmt.Errorf("amount out of range")
+	}
+	return quo.Int64(), nil
+}
+
+// mul returns a*b and whether the product fits in an int64.
+func mul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
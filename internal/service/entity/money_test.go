@@ -0,0 +1,162 @@
+package entity
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      string
+		currency    string
+		expect      Money
+		expectedErr string
+	}{
+		{name: "decimal", amount: "100.50", currency: "EUR", expect: Money{Amount: 10050, Currency: "EUR"}},
+		{name: "integer", amount: "100", currency: "EUR", expect: Money{Amount: 10000, Currency: "EUR"}},
+		{name: "exponent", amount: "1e2", currency: "EUR", expect: Money{Amount: 10000, Currency: "EUR"}},
+		{name: "negative", amount: "-0.5", currency: "EUR", expect: Money{Amount: -50, Currency: "EUR"}},
+		{name: "extra digits rounded", amount: "0.105", currency: "EUR", expect: Money{Amount: 11, Currency: "EUR"}},
+		{name: "no minor units", amount: "1500.4", currency: "JPY", expect: Money{Amount: 1500, Currency: "JPY"}},
+		{name: "three minor units", amount: "1.2345", currency: "KWD", expect: Money{Amount: 1235, Currency: "KWD"}},
+		{name: "float trap", amount: "0.1", currency: "EUR", expect: Money{Amount: 10, Currency: "EUR"}},
+		{name: "invalid amount", amount: "ten", currency: "EUR", expectedErr: `invalid amount "ten"`},
+		{name: "unknown currency", amount: "10", currency: "XXX", expectedErr: `unknown currency "XXX"`},
+		{name: "out of range", amount: "1e30", currency: "EUR", expectedErr: "amount out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			money, err := ParseMoney(tt.amount, tt.currency)
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, money)
+		})
+	}
+}
+
+func TestMoney_MulDiv(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     RoundingMode
+		amount   int64
+		num, den int64
+		expect   int64
+	}{
+		{name: "half up tie", mode: RoundHalfUp, amount: 25, num: 1, den: 2, expect: 13},
+		{name: "half up negative tie", mode: RoundHalfUp, amount: -25, num: 1, den: 2, expect: -13},
+		{name: "half even tie down", mode: RoundHalfEven, amount: 25, num: 1, den: 2, expect: 12},
+		{name: "half even tie up", mode: RoundHalfEven, amount: 35, num: 1, den: 2, expect: 18},
+		{name: "half even above tie", mode: RoundHalfEven, amount: 10055, num: 15, den: 100, expect: 1508},
+		{name: "down", mode: RoundDown, amount: 10055, num: 15, den: 100, expect: 1508},
+		{name: "up", mode: RoundUp, amount: 10001, num: 1, den: 100, expect: 101},
+		{name: "exact", mode: RoundUp, amount: 10000, num: 15, den: 100, expect: 1500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, RegisterCurrency(Currency{Code: "TST", MinorUnits: 2, Rounding: tt.mode}))
+
+			result := Money{Amount: tt.amount, Currency: "TST"}.MulDiv(tt.num, tt.den)
+			assert.Equal(t, Money{Amount: tt.expect, Currency: "TST"}, result)
+		})
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	a := Money{Amount: 1050, Currency: "EUR"}
+	b := Money{Amount: 300, Currency: "EUR"}
+
+	assert.Equal(t, Money{Amount: 1350, Currency: "EUR"}, a.Add(b))
+	assert.Equal(t, Money{Amount: 750, Currency: "EUR"}, a.Sub(b))
+	assert.Equal(t, Money{Amount: 3150, Currency: "EUR"}, a.Mul(3))
+	assert.Equal(t, b, a.Min(b))
+	assert.Equal(t, a, Money{}.Add(a))
+	assert.Equal(t, 10.5, a.Float())
+
+	five, err := MajorUnits(5, "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, Money{Amount: 5, Currency: "JPY"}, five)
+}
+
+func TestMoney_Overflow(t *testing.T) {
+	_, err := MajorUnits(92233720368547759, "EUR")
+	assert.ErrorContains(t, err, "amount out of range")
+	_, err = MajorUnits(-92233720368547759, "EUR")
+	assert.ErrorContains(t, err, "amount out of range")
+
+	largest := Money{Amount: math.MaxInt64, Currency: "EUR"}
+	assert.Panics(t, func() { largest.Add(Money{Amount: 1}) })
+	assert.Panics(t, func() { Money{Amount: math.MinInt64}.Sub(Money{Amount: 1}) })
+	assert.Panics(t, func() { largest.Mul(2) })
+	assert.Panics(t, func() { Money{Amount: math.MinInt64}.Mul(-1) })
+	assert.Equal(t, Money{Amount: -math.MaxInt64, Currency: "EUR"}, largest.Mul(-1))
+}
+
+func TestMoney_Convert(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		ratio    *big.Rat
+		currency string
+		expect   Money
+	}{
+		{name: "rounded to cents", money: Money{Amount: 1000, Currency: "EUR"}, ratio: big.NewRat(43125, 10000), currency: "PLN", expect: Money{Amount: 4313, Currency: "PLN"}},
+		{name: "to no minor units", money: Money{Amount: 1050, Currency: "EUR"}, ratio: big.NewRat(163, 1), currency: "JPY", expect: Money{Amount: 1712, Currency: "JPY"}},
+		{name: "from no minor units", money: Money{Amount: 1000, Currency: "JPY"}, ratio: big.NewRat(6, 1000), currency: "EUR", expect: Money{Amount: 600, Currency: "EUR"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.money.Convert(tt.ratio, tt.currency)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, result)
+		})
+	}
+}
+
+func TestMoney_In(t *testing.T) {
+	zero, err := Money{}.In("USD")
+	assert.NoError(t, err)
+	assert.Equal(t, Money{Currency: "USD"}, zero)
+
+	_, err = Money{Amount: 100, Currency: "EUR"}.In("USD")
+	assert.ErrorContains(t, err, "amount in EUR, want USD")
+}
+
+func TestMoney_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		money  Money
+		expect string
+	}{
+		{money: Money{Amount: 10050, Currency: "EUR"}, expect: "100.50"},
+		{money: Money{Amount: -5, Currency: "EUR"}, expect: "-0.05"},
+		{money: Money{Amount: 1500, Currency: "JPY"}, expect: "1500"},
+		{money: Money{Amount: 1235, Currency: "KWD"}, expect: "1.235"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expect, func(t *testing.T) {
+			data, err := json.Marshal(struct{ Value Money }{tt.money})
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"Value": `+tt.expect+`}`, string(data))
+		})
+	}
+}
+
+func TestRegisterCurrency_Invalid(t *testing.T) {
+	assert.ErrorContains(t, RegisterCurrency(Currency{Code: "eur", MinorUnits: 2, Rounding: RoundHalfUp}), "invalid currency code")
+	assert.ErrorContains(t, RegisterCurrency(Currency{Code: "TST", MinorUnits: 9, Rounding: RoundHalfUp}), "minor units")
+	assert.ErrorContains(t, RegisterCurrency(Currency{Code: "TST", MinorUnits: 2, Rounding: "nearest"}), "unknown rounding mode")
+	assert.ErrorContains(t, SetRounding("XXX", RoundDown), `unknown currency "XXX"`)
+}
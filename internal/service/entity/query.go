@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"cmp"
+	"strings"
+	"time"
+)
+
+// CouponSort is the field coupons are listed by
+type CouponSort string
+
+const (
+	// SortByCode orders coupons by code
+	SortByCode CouponSort = "code"
+	// SortByExpiry orders coupons by expiry time; coupons that never
+	// expire come last
+	SortByExpiry CouponSort = "expiresAt"
+	// SortByDiscount orders coupons by discount value
+	SortByDiscount CouponSort = "discount"
+)
+
+// CouponCursor is the position of the last coupon of a page: its sort
+// values and code, which breaks ties
+type CouponCursor struct {
+	Code      string    `json:"c"`
+	ExpiresAt time.Time `json:"e,omitempty"`
+	Discount  int       `json:"d,omitempty"`
+}
+
+// CursorOf returns the position of the coupon
+func CursorOf(coupon Coupon) CouponCursor {
+	return CouponCursor{Code: coupon.Code, ExpiresAt: coupon.ExpiresAt, Discount: coupon.Discount}
+}
+
+// CouponQuery filters and orders coupons. Empty filters match every
+// coupon. After, if set, skips every coupon up to and including that
+// position.
+type CouponQuery struct {
+	Status        CouponStatus
+	CampaignID    string
+	Type          DiscountType
+	ExpiresBefore time.Time
+	CodePrefix    string
+
+	Sort       CouponSort
+	Descending bool
+	After      *CouponCursor
+	Limit      int
+}
+
+// Matches reports whether the coupon passes the filters of the query
+func (q CouponQuery) Matches(coupon Coupon) bool {
+	if q.Status != "" && coupon.Status != q.Status {
+		return false
+	}
+	if q.CampaignID != "" && coupon.CampaignID != q.CampaignID {
+		return false
+	}
+	if q.Type != "" && coupon.Type != q.Type {
+		return false
+	}
+	if !q.ExpiresBefore.IsZero() && (coupon.ExpiresAt.IsZero() || !coupon.ExpiresAt.Before(q.ExpiresBefore)) {
+		return false
+	}
+	if q.CodePrefix != "" && !strings.HasPrefix(coupon.Code, q.CodePrefix) {
+		return false
+	}
+	return q.After == nil || q.Compare(CursorOf(coupon), *q.After) > 0
+}
+
+// Compare orders two positions by the sort of the query, then by code
+func (q CouponQuery) Compare(a, b CouponCursor) int {
+	var order int
+	switch q.Sort {
+	case SortByExpiry:
+		order = compareExpiry(a.ExpiresAt, b.ExpiresAt)
+	case SortByDiscount:
+		order = cmp.Compare(a.Discount, b.Discount)
+	}
+	if order == 0 {
+		order = strings.Compare(a.Code, b.Code)
+	}
+	if q.Descending {
+		return -order
+	}
+	return order
+}
+
+// compareExpiry orders expiry times with a zero time, which never expires,
+// after every other.
+func compareExpiry(a, b time.Time) int {
+	switch {
+	case a.Equal(b):
+		return 0
+	case a.IsZero():
+		return 1
+	case b.IsZero():
+		return -1
+	}
+	return a.Compare(b)
+}
+
+// CouponPage is a page of a coupon listing
+// @Description Page of coupons; pass nextCursor to get the following page
+type CouponPage struct {
+	Coupons    []Coupon `json:"coupons"`
+	NextCursor string   `json:"nextCursor,omitempty" example:"eyJjIjoiU1VNTUVSMjAyNCJ9"`
+}
@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ExchangeRate is the price of one unit of From in To, as an exact decimal
+// @Description Exchange rate; one unit of the from currency costs rate units of the to currency
+type ExchangeRate struct {
+	Version   int       `json:"version,omitempty" example:"3"`
+	From      string    `json:"from" example:"EUR"`
+	To        string    `json:"to" example:"PLN"`
+	Rate      string    `json:"rate" example:"4.3125"`
+	ValidFrom time.Time `json:"validFrom,omitempty" example:"2024-06-01T00:00:00Z"`
+}
+
+// Ratio returns the rate as an exact fraction
+func (r ExchangeRate) Ratio() (*big.Rat, error) {
+	ratio, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || ratio.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q for %s/%s", r.Rate, r.From, r.To)
+	}
+	return ratio, nil
+}
+
+// RateTable is a version of the exchange-rate table. A table takes effect
+// at ValidFrom and stays in effect until a table with a later ValidFrom
+// does.
+// @Description Versioned set of exchange rates
+type RateTable struct {
+	Version   int            `json:"version" example:"3"`
+	ValidFrom time.Time      `json:"validFrom" example:"2024-06-01T00:00:00Z"`
+	Rates     []ExchangeRate `json:"rates"`
+}
+
+// Find returns the rate that converts from into to, using the rate of the
+// opposite pair when only that one is listed. The returned rate is stamped
+// with the table version and the ratio is oriented from -> to.
+func (t RateTable) Find(from, to string) (*ExchangeRate, *big.Rat, error) {
+	for _, rate := range t.Rates {
+		if rate.From == from && rate.To == to {
+			return t.stamp(rate, false)
+		}
+	}
+	for _, rate := range t.Rates {
+		if rate.From == to && rate.To == from {
+			return t.stamp(rate, true)
+		}
+	}
+	return nil, nil, fmt.Errorf("no exchange rate from %s to %s in version %d", from, to, t.Version)
+}
+
+func (t RateTable) stamp(rate ExchangeRate, inverse bool) (*ExchangeRate, *big.Rat, error) {
+	ratio, err := rate.Ratio()
+	if err != nil {
+		return nil, nil, err
+	}
+	if inverse {
+		ratio.Inv(ratio)
+	}
+	rate.Version = t.Version
+	rate.ValidFrom = t.ValidFrom
+	return &rate, ratio, nil
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// Redemption records a customer using a coupon
+type Redemption struct {
+	ID             string
+	Code           string
+	CampaignID     string
+	CustomerID     string
+	DiscountAmount Money
+	RedeemedAt     time.Time
+	// CouponVersionID names the coupon terms the discount was granted by
+	CouponVersionID string
+}
+
+// Reservation holds a coupon for a customer until the order is placed or
+// cancelled
+type Reservation struct {
+	ID             string
+	Code           string
+	CampaignID     string
+	CustomerID     string
+	DiscountAmount Money
+	ReservedAt     time.Time
+	ExpiresAt      time.Time
+	// CouponVersionID names the coupon terms the discount was granted by
+	CouponVersionID string
+}
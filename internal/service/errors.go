@@ -0,0 +1,112 @@
+package service
+
+import "errors"
+
+// ErrInvalidRequest is returned when the input of an operation is
+// incomplete or malformed, such as an empty coupon code.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// ErrCouponNotFound is returned when no coupon has the requested code.
+var ErrCouponNotFound = errors.New("coupon not found")
+
+// ErrCouponVersionNotFound is returned when a coupon has no version with
+// the requested ID or number, or none at the requested time.
+var ErrCouponVersionNotFound = errors.New("coupon version not found")
+
+// ErrBelowMinBasketValue is returned when a basket does not reach the
+// minimum value required by a coupon.
+var ErrBelowMinBasketValue = errors.New("basket value below coupon minimum")
+
+// ErrInvalidCoupon is returned when a coupon definition is not usable.
+var ErrInvalidCoupon = errors.New("invalid coupon")
+
+// ErrCouponNotYetActive is returned when a coupon is applied before its start time.
+var ErrCouponNotYetActive = errors.New("coupon not yet active")
+
+// ErrCouponExpired is returned when a coupon is applied after its expiry time.
+var ErrCouponExpired = errors.New("coupon expired")
+
+// ErrRedemptionLimitReached is returned when a coupon has been redeemed the
+// maximum number of times.
+var ErrRedemptionLimitReached = errors.New("coupon redemption limit reached")
+
+// ErrCustomerLimitReached is returned when a customer has redeemed a coupon
+// the maximum number of times allowed per customer.
+var ErrCustomerLimitReached = errors.New("coupon redemption limit reached for customer")
+
+// ErrCustomerRequired is returned when a coupon limited per customer is
+// applied without a customer ID.
+var ErrCustomerRequired = errors.New("customer id required")
+
+// ErrCouponNotAssigned is returned when a personal coupon is applied by a
+// customer it is not assigned to.
+var ErrCouponNotAssigned = errors.New("coupon not assigned to customer")
+
+// ErrReservationNotFound is returned when a reservation does not exist or
+// has already been committed or released.
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// ErrReservationExpired is returned when a reservation is committed after
+// it expired.
+var ErrReservationExpired = errors.New("reservation expired")
+
+// ErrNoEligibleItems is returned when none of the basket lines match the
+// coupon targeting, or the coupon takes nothing off the basket.
+var ErrNoEligibleItems = errors.New("no eligible items in basket")
+
+// ErrCouponNotCombinable is returned for a coupon left out of a
+// combination because it or a chosen coupon is not stackable.
+var ErrCouponNotCombinable = errors.New("coupon not combinable")
+
+// ErrExclusivityConflict is returned for a coupon left out of a
+// combination because a chosen coupon is in the same exclusivity group.
+var ErrExclusivityConflict = errors.New("exclusivity group already used")
+
+// ErrNoAddedDiscount is returned for a coupon left out of a combination
+// because adding it does not increase the discount.
+var ErrNoAddedDiscount = errors.New("no added discount")
+
+// ErrCampaignNotFound is returned when a campaign does not exist.
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+// ErrCampaignPaused is returned when a coupon belongs to a paused campaign.
+var ErrCampaignPaused = errors.New("campaign paused")
+
+// ErrCampaignBudgetExhausted is returned when a discount would exceed the
+// budget of the coupon campaign.
+var ErrCampaignBudgetExhausted = errors.New("campaign budget exhausted")
+
+// ErrRuleNotSatisfied is returned when the basket or customer does not meet
+// the coupon eligibility rule.
+var ErrRuleNotSatisfied = errors.New("coupon conditions not met")
+
+// ErrCurrencyMismatch is returned when amounts in different currencies meet,
+// such as a coupon applied to a basket in another currency.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ErrInvalidRate is returned when an exchange-rate table is not usable.
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// ErrRateNotFound is returned when no exchange-rate table is in effect or a
+// version does not exist.
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// ErrCouponNotActive is returned when a coupon that is not active is
+// applied.
+var ErrCouponNotActive = errors.New("coupon not active")
+
+// ErrInvalidTransition is returned when a coupon cannot move to the
+// requested lifecycle status from its current one.
+var ErrInvalidTransition = errors.New("invalid coupon status transition")
+
+// ErrVersionConflict is returned when a coupon is updated or deleted based
+// on a version that is no longer current.
+var ErrVersionConflict = errors.New("coupon version conflict")
+
+// ErrCouponExists is returned when a coupon is created with a code that is
+// already taken.
+var ErrCouponExists = errors.New("coupon code already exists")
+
+// ErrInvalidCursor is returned when a listing cursor is malformed or was
+// issued for another sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"math/big"
+	. "reviewsch/internal/service/entity"
+	"time"
+)
+
+// LoadRates stores a new version of the exchange-rate table. A table
+// without a start time takes effect immediately.
+func (s *Service) LoadRates(table RateTable) (*RateTable, error) {
+	if len(table.Rates) == 0 {
+		return nil, fmt.Errorf("%w: no rates", ErrInvalidRate)
+	}
+
+	seen := make(map[[2]string]bool, len(table.Rates))
+	for i, rate := range table.Rates {
+		for _, code := range []string{rate.From, rate.To} {
+			if _, err := LookupCurrency(code); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidRate, err)
+			}
+		}
+		if rate.From == rate.To {
+			return nil, fmt.Errorf("%w: rate from %s to itself", ErrInvalidRate, rate.From)
+		}
+		if _, err := rate.Ratio(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRate, err)
+		}
+		pair := [2]string{rate.From, rate.To}
+		if seen[pair] || seen[[2]string{rate.To, rate.From}] {
+			return nil, fmt.Errorf("%w: duplicate rate for %s/%s", ErrInvalidRate, rate.From, rate.To)
+		}
+		seen[pair] = true
+
+		table.Rates[i] = ExchangeRate{From: rate.From, To: rate.To, Rate: rate.Rate}
+	}
+
+	if table.ValidFrom.IsZero() {
+		table.ValidFrom = s.now()
+	}
+	return s.rates.Save(table)
+}
+
+// GetRates returns the exchange-rate table in effect at the given time.
+func (s *Service) GetRates(at time.Time) (*RateTable, error) {
+	return s.rates.Active(at)
+}
+
+// GetRateVersion returns a version of the exchange-rate table.
+func (s *Service) GetRateVersion(version int) (*RateTable, error) {
+	return s.rates.FindByVersion(version)
+}
+
+// localTerms returns the coupon terms in the basket currency. A coupon can
+// be used in its own currency, with an explicit fixed amount for the
+// basket currency, or with any currency the exchange-rate table in effect
+// at the given time converts to. The rate is returned when it was used.
+func (s *Service) localTerms(coupon *Coupon, currency string, at time.Time) (*Coupon, *ExchangeRate, error) {
+	from := coupon.Currency
+	if from == currency {
+		return coupon, nil, nil
+	}
+
+	local := *coupon
+	local.Currency = currency
+	_, explicit := coupon.AmountIn(currency)
+	if explicit && coupon.MinBasketValue.IsZero() {
+		local.MinBasketValue = Money{Currency: currency}
+		return &local, nil, nil
+	}
+
+	rate, ratio, err := s.rate(from, currency, at)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: coupon in %s, basket in %s: %v", ErrCurrencyMismatch, from, currency, err)
+	}
+
+	if local.MinBasketValue, err = coupon.MinBasketValue.Convert(ratio, currency); err != nil {
+		return nil, nil, err
+	}
+	if coupon.Type == DiscountFixedAmount && !explicit {
+		amount, err := MajorUnits(int64(coupon.Discount), from)
+		if err != nil {
+			return nil, nil, err
+		}
+		converted, err := amount.Convert(ratio, currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		local.Amounts = maps.Clone(coupon.Amounts)
+		if local.Amounts == nil {
+			local.Amounts = make(map[string]Money, 1)
+		}
+		local.Amounts[currency] = converted
+	}
+	return &local, rate, nil
+}
+
+// convert returns the amount in another currency, using the exchange-rate
+// table in effect at the given time.
+func (s *Service) convert(amount Money, currency string, at time.Time) (Money, error) {
+	if amount.Currency == "" || amount.Currency == currency {
+		return amount.In(currency)
+	}
+	_, ratio, err := s.rate(amount.Currency, currency, at)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %v", ErrCurrencyMismatch, err)
+	}
+	return amount.Convert(ratio, currency)
+}
+
+// rate looks up the rate between two currencies in the exchange-rate table
+// in effect at the given time.
+func (s *Service) rate(from, to string, at time.Time) (*ExchangeRate, *big.Rat, error) {
+	table, err := s.rates.Active(at)
+	if err != nil {
+		return nil, nil, err
+	}
+	return table.Find(from, to)
+}
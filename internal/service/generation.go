@@ -0,0 +1,167 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	. "reviewsch/internal/service/entity"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxGeneratedCodes is the maximum number of codes minted per request.
+	MaxGeneratedCodes = 100000
+	// GenerationBatchSize is the number of coupons written to the
+	// repository at once.
+	GenerationBatchSize = 1000
+
+	defaultCodeLength   = 8
+	defaultCodeAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	ambiguousCharacters = "0Oo1IiLl"
+)
+
+// GenerateCoupons mints spec.Count unique single-use coupons sharing the
+// terms of the template and returns their codes. All codes are picked
+// before anything is written; if writing a batch fails the batches written
+// before it are removed again, so either every coupon is created or none.
+// Each coupon is recorded as created by the actor.
+func (s *Service) GenerateCoupons(template Coupon, spec CodeSpec, actor Actor) ([]string, error) {
+	if spec.Count <= 0 || spec.Count > MaxGeneratedCodes {
+		return nil, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidCoupon, MaxGeneratedCodes)
+	}
+	template.MaxRedemptions = 1
+	if err := prepareTerms(&template); err != nil {
+		return nil, err
+	}
+	if err := s.checkCouponCampaign(template); err != nil {
+		return nil, err
+	}
+
+	alphabet, length, err := codeAlphabet(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, spec.Count)
+	coupons := make([]Coupon, 0, spec.Count)
+	seen := make(map[string]bool, spec.Count)
+	for len(codes) < spec.Count {
+		code, err := randomCode(spec.Prefix, alphabet, length)
+		if err != nil {
+			return nil, err
+		}
+		if seen[code] {
+			continue
+		}
+		if _, err := s.repo.FindByCode(code); err == nil {
+			continue
+		} else if !errors.Is(err, ErrCouponNotFound) {
+			return nil, err
+		}
+		seen[code] = true
+
+		coupon := template
+		coupon.ID = uuid.NewString()
+		coupon.Code = code
+		coupon.Version = 1
+		coupons = append(coupons, coupon)
+		codes = append(codes, code)
+	}
+
+	for start := 0; start < len(coupons); start += GenerationBatchSize {
+		batch := coupons[start:min(start+GenerationBatchSize, len(coupons))]
+		if err := s.repo.SaveBatch(batch); err != nil {
+			return nil, errors.Join(err, s.removeGenerated(coupons[:start]))
+		}
+	}
+
+	for start := 0; start < len(coupons); start += GenerationBatchSize {
+		batch := coupons[start:min(start+GenerationBatchSize, len(coupons))]
+		now := s.now()
+		if err := s.keepVersions(now, batch...); err != nil {
+			return nil, err
+		}
+		entries := make([]AuditEntry, len(batch))
+		for i := range batch {
+			entries[i] = auditEntry(actor, AuditCreate, nil, &batch[i], now)
+		}
+		if err := s.recordAll(entries...); err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+// removeGenerated deletes coupons written by a generation that failed
+// part way.
+func (s *Service) removeGenerated(coupons []Coupon) error {
+	var errs []error
+	for _, coupon := range coupons {
+		if err := s.repo.Delete(coupon.Code, coupon.Version); err != nil {
+			errs = append(errs, fmt.Errorf("removing generated coupon %s: %w", coupon.Code, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// codeAlphabet resolves the alphabet and length of the random part of the
+// codes and checks that they leave enough room for the requested count.
+func codeAlphabet(spec CodeSpec) ([]rune, int, error) {
+	alphabet := spec.Alphabet
+	if alphabet == "" {
+		alphabet = defaultCodeAlphabet
+	}
+	if spec.AvoidAmbiguous {
+		alphabet = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(ambiguousCharacters, r) {
+				return -1
+			}
+			return r
+		}, alphabet)
+	}
+
+	var unique []rune
+	for _, r := range alphabet {
+		if !strings.ContainsRune(string(unique), r) {
+			unique = append(unique, r)
+		}
+	}
+	if len(unique) < 2 {
+		return nil, 0, fmt.Errorf("%w: alphabet needs at least two distinct characters", ErrInvalidCoupon)
+	}
+
+	length := spec.Length
+	if length == 0 {
+		length = defaultCodeLength
+	}
+
+	// Require twice as many possible codes as requested so that collisions
+	// stay rare while generating.
+	if math.Pow(float64(len(unique)), float64(length)) < 2*float64(spec.Count) {
+		return nil, 0, fmt.Errorf("%w: %d characters of length %d cannot produce %d unique codes",
+			ErrInvalidCoupon, len(unique), length, spec.Count)
+	}
+
+	return unique, length, nil
+}
+
+// randomCode returns the prefix followed by length characters picked
+// uniformly from the alphabet.
+func randomCode(prefix string, alphabet []rune, length int) (string, error) {
+	var b strings.Builder
+	b.WriteString(prefix)
+	size := big.NewInt(int64(len(alphabet)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", fmt.Errorf("generating code: %w", err)
+		}
+		b.WriteRune(alphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"fmt"
+	. "reviewsch/internal/service/entity"
+	"time"
+)
+
+// ListCouponVersions returns every recorded version of the coupons with the
+// code, oldest first.
+func (s *Service) ListCouponVersions(code string) ([]CouponVersion, error) {
+	if code == "" {
+		return nil, fmt.Errorf("%w: empty coupon code", ErrInvalidRequest)
+	}
+	versions, err := s.history.Versions(code)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrCouponNotFound
+	}
+	return versions, nil
+}
+
+// GetCouponVersion returns a version of the coupon by number. When the code
+// was deleted and created again the number refers to the latest coupon.
+func (s *Service) GetCouponVersion(code string, version int) (*CouponVersion, error) {
+	versions, err := s.ListCouponVersions(code)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Version == version {
+			return &versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has no version %d", ErrCouponVersionNotFound, code, version)
+}
+
+// GetCouponAsOf returns the version of the coupon in effect at the given
+// time, the last one recorded until then. There is none while the code was
+// deleted.
+func (s *Service) GetCouponAsOf(code string, at time.Time) (*CouponVersion, error) {
+	versions, err := s.ListCouponVersions(code)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].RecordedAt.After(at) {
+			continue
+		}
+		if versions[i].Deleted {
+			return nil, fmt.Errorf("%w: %s was deleted at %s", ErrCouponVersionNotFound, code, versions[i].RecordedAt.Format(time.RFC3339))
+		}
+		return &versions[i], nil
+	}
+	return nil, fmt.Errorf("%w: %s did not exist at %s", ErrCouponVersionNotFound, code, at.Format(time.RFC3339))
+}
+
+// GetCouponVersionByID returns the version named in an application result.
+func (s *Service) GetCouponVersionByID(id string) (*CouponVersion, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: empty version id", ErrInvalidRequest)
+	}
+	return s.history.FindVersion(id)
+}
+
+// DiffCouponVersions lists the fields changed from one version of the
+// coupon to another.
+func (s *Service) DiffCouponVersions(code string, from, to int) (*CouponDiff, error) {
+	before, err := s.GetCouponVersion(code, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.GetCouponVersion(code, to)
+	if err != nil {
+		return nil, err
+	}
+	return &CouponDiff{
+		Code:    code,
+		From:    from,
+		To:      to,
+		Changes: couponChanges(&before.Coupon, &after.Coupon),
+	}, nil
+}
+
+// keepVersions records the coupons as stored at the given time.
+func (s *Service) keepVersions(at time.Time, coupons ...Coupon) error {
+	versions := make([]CouponVersion, len(coupons))
+	for i, coupon := range coupons {
+		versions[i] = CouponVersion{
+			ID:         coupon.VersionID(),
+			Code:       coupon.Code,
+			Version:    coupon.Version,
+			RecordedAt: at,
+			Coupon:     coupon,
+		}
+	}
+	if err := s.history.Append(versions...); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	return nil
+}
+
+// keepDeletion records that the coupon was deleted at the given time, as a
+// version after its last one that is marked deleted.
+func (s *Service) keepDeletion(at time.Time, coupon Coupon) error {
+	coupon.Version++
+	err := s.history.Append(CouponVersion{
+		ID:         coupon.VersionID(),
+		Code:       coupon.Code,
+		Version:    coupon.Version,
+		RecordedAt: at,
+		Deleted:    true,
+		Coupon:     coupon,
+	})
+	if err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	. "reviewsch/internal/service/entity"
+	"slices"
+)
+
+// ActivateCoupon makes a draft coupon applicable.
+func (s *Service) ActivateCoupon(code string, actor Actor) (*Coupon, error) {
+	return s.transition(code, actor, CouponActive, CouponDraft)
+}
+
+// PauseCoupon temporarily stops an active coupon from being applied.
+func (s *Service) PauseCoupon(code string, actor Actor) (*Coupon, error) {
+	return s.transition(code, actor, CouponPaused, CouponActive)
+}
+
+// ResumeCoupon makes a paused coupon applicable again.
+func (s *Service) ResumeCoupon(code string, actor Actor) (*Coupon, error) {
+	return s.transition(code, actor, CouponActive, CouponPaused)
+}
+
+// ArchiveCoupon stops a coupon from being applied for good. The coupon and
+// its redemptions are kept.
+func (s *Service) ArchiveCoupon(code string, actor Actor) (*Coupon, error) {
+	return s.transition(code, actor, CouponArchived, CouponDraft, CouponActive, CouponPaused)
+}
+
+// transition moves the coupon to the status if it is in one of the from
+// states. The repository only changes the status if it was not changed
+// concurrently. The change is recorded as done by the actor.
+func (s *Service) transition(code string, actor Actor, to CouponStatus, from ...CouponStatus) (*Coupon, error) {
+	if code == "" {
+		return nil, fmt.Errorf("%w: empty coupon code", ErrInvalidRequest)
+	}
+	coupon, err := s.repo.FindByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(from, coupon.Status) {
+		return nil, fmt.Errorf("%w: coupon is %s, cannot become %s", ErrInvalidTransition, coupon.Status, to)
+	}
+	changed, err := s.repo.SetStatus(code, coupon.Status, to)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(actor, AuditStatus, coupon, changed); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// checkStatus verifies that the coupon can be applied.
+func checkStatus(coupon *Coupon) error {
+	if coupon.Status != CouponActive {
+		return fmt.Errorf("%w: coupon is %s", ErrCouponNotActive, coupon.Status)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	. "reviewsch/internal/service/entity"
+)
+
+const (
+	// DefaultPageSize is the number of coupons listed per page when the
+	// query does not set a limit.
+	DefaultPageSize = 50
+	// MaxPageSize is the largest page ListCoupons returns.
+	MaxPageSize = 200
+)
+
+// cursor is the opaque continuation token of a listing. It records the sort
+// order so it cannot be used with another one.
+type cursor struct {
+	Sort       CouponSort `json:"s"`
+	Descending bool       `json:"r,omitempty"`
+	CouponCursor
+}
+
+// ListCoupons returns a page of the coupons matching the query. The cursor
+// is empty for the first page and the NextCursor of the previous page
+// otherwise.
+func (s *Service) ListCoupons(query CouponQuery, after string) (*CouponPage, error) {
+	switch query.Sort {
+	case "":
+		query.Sort = SortByCode
+	case SortByCode, SortByExpiry, SortByDiscount:
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidRequest, query.Sort)
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+	if query.Limit > MaxPageSize {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidRequest, MaxPageSize)
+	}
+
+	query.After = nil
+	if after != "" {
+		position, err := decodeCursor(after, query)
+		if err != nil {
+			return nil, err
+		}
+		query.After = position
+	}
+
+	// One extra coupon tells whether there is a next page
+	limit := query.Limit
+	query.Limit++
+	coupons, err := s.repo.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &CouponPage{Coupons: coupons}
+	if len(coupons) > limit {
+		page.Coupons = coupons[:limit]
+		page.NextCursor = encodeCursor(query, CursorOf(coupons[limit-1]))
+	}
+	if page.Coupons == nil {
+		page.Coupons = []Coupon{}
+	}
+	return page, nil
+}
+
+func encodeCursor(query CouponQuery, position CouponCursor) string {
+	data, _ := json.Marshal(cursor{Sort: query.Sort, Descending: query.Descending, CouponCursor: position})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string, query CouponQuery) (*CouponCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Code == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != query.Sort || c.Descending != query.Descending {
+		return nil, fmt.Errorf("%w: issued for another sort order", ErrInvalidCursor)
+	}
+	return &c.CouponCursor, nil
+}
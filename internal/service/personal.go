@@ -0,0 +1,64 @@
+package service
+
+import (
+	. "reviewsch/internal/service/entity"
+	"sort"
+)
+
+// CustomerCoupons returns the personal coupons assigned to the customer
+// that are active, have not expired and that the customer has not used or
+// reserved yet, those expiring first at the top. Coupons whose total
+// redemption limit other customers have used up are left out as well.
+func (s *Service) CustomerCoupons(customer Customer) ([]AssignedCoupon, error) {
+	if customer.ID == "" {
+		return nil, ErrCustomerRequired
+	}
+
+	now := s.now()
+	coupons, err := s.repo.FindAssigned(customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned := []AssignedCoupon{}
+	for _, coupon := range coupons {
+		if coupon.Status != CouponActive || !coupon.ExpiresAt.IsZero() && !now.Before(coupon.ExpiresAt) {
+			continue
+		}
+		total, perCustomer, err := s.ledger.Usage(coupon.Code, customer.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		if perCustomer > 0 || coupon.MaxRedemptions > 0 && total >= coupon.MaxRedemptions {
+			continue
+		}
+
+		found := AssignedCoupon{
+			Code:           coupon.Code,
+			Type:           coupon.Type,
+			Discount:       coupon.Discount,
+			Currency:       coupon.Currency,
+			MinBasketValue: coupon.MinBasketValue,
+			Stackable:      coupon.Stackable,
+		}
+		if !coupon.StartsAt.IsZero() {
+			found.StartsAt = &coupon.StartsAt
+		}
+		if !coupon.ExpiresAt.IsZero() {
+			found.ExpiresAt = &coupon.ExpiresAt
+		}
+		assigned = append(assigned, found)
+	}
+
+	sort.Slice(assigned, func(i, j int) bool {
+		a, b := assigned[i].ExpiresAt, assigned[j].ExpiresAt
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		if a != nil && !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return assigned[i].Code < assigned[j].Code
+	})
+	return assigned, nil
+}
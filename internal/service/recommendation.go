@@ -0,0 +1,88 @@
+package service
+
+import (
+	. "reviewsch/internal/service/entity"
+	"sort"
+)
+
+// MaxAlternatives is the number of alternative combinations a
+// recommendation lists.
+const MaxAlternatives = 10
+
+// RecommendCoupons picks the combination of codes that gives the largest
+// valid discount, exactly as ApplyCoupons would, without redeeming
+// anything. The other valid combinations are listed as alternatives,
+// largest saving first; codes that cannot be applied at all are dropped
+// with their reason.
+func (s *Service) RecommendCoupons(basket Basket, codes []string, customer Customer) (*Recommendation, error) {
+	if err := normalizeBasket(&basket); err != nil {
+		return nil, err
+	}
+
+	candidates, dropped, err := s.candidates(basket, codes, customer, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	chosen, _ := resolve(candidates)
+	application := combine(basket, chosen, nil)
+
+	recommendation := &Recommendation{
+		Codes:        setCodes(chosen),
+		Savings:      application.Basket.DiscountAmount,
+		Basket:       application.Basket,
+		Alternatives: []CouponSet{},
+		Dropped:      dropped,
+	}
+	if recommendation.Dropped == nil {
+		recommendation.Dropped = []DroppedCoupon{}
+	}
+
+	for _, set := range combinations(candidates) {
+		if sameSet(set, chosen) {
+			continue
+		}
+		result := combine(basket, set, nil).Basket
+		recommendation.Alternatives = append(recommendation.Alternatives, CouponSet{
+			Codes:      setCodes(set),
+			Savings:    result.DiscountAmount,
+			FinalValue: result.FinalValue,
+		})
+	}
+
+	// Like resolve, ties go to the combination with fewer coupons
+	alternatives := recommendation.Alternatives
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		if alternatives[i].Savings.Amount != alternatives[j].Savings.Amount {
+			return alternatives[i].Savings.Amount > alternatives[j].Savings.Amount
+		}
+		return len(alternatives[i].Codes) < len(alternatives[j].Codes)
+	})
+	if len(alternatives) > MaxAlternatives {
+		recommendation.Alternatives = alternatives[:MaxAlternatives]
+	}
+
+	return recommendation, nil
+}
+
+func setCodes(set []candidate) []string {
+	codes := make([]string, len(set))
+	for i, c := range set {
+		codes[i] = c.coupon.Code
+	}
+	return codes
+}
+
+// sameSet reports whether both sets hold the same coupons. Sets keep the
+// candidate order, so comparing in order is enough.
+func sameSet(a, b []candidate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].coupon.Code != b[i].coupon.Code {
+			return false
+		}
+	}
+	return true
+}
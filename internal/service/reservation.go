@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	. "reviewsch/internal/service/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReserveCoupon evaluates the coupon like ApplyCoupon but only reserves the
+// redemption. The discount is charged to the coupon campaign, if any, right
+// away, so reservations cannot overdraw its budget. The reservation must be
+// committed with CommitReservation once the order is placed, or released
+// with ReleaseReservation when it is cancelled. Reservations that are never
+// committed expire after the reservation TTL; releasing or expiring a
+// reservation gives its discount back to the campaign.
+func (s *Service) ReserveCoupon(basket Basket, code string, customer Customer) (*Basket, error) {
+	now := s.now()
+	if err := s.refundExpired(now); err != nil {
+		return nil, err
+	}
+	coupon, result, err := s.evaluate(basket, code, customer, now, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation := Reservation{
+		ID:              uuid.NewString(),
+		Code:            coupon.Code,
+		CampaignID:      coupon.CampaignID,
+		CustomerID:      customer.ID,
+		DiscountAmount:  result.DiscountAmount,
+		ReservedAt:      now,
+		ExpiresAt:       now.Add(s.reservationTTL),
+		CouponVersionID: result.CouponVersionID,
+	}
+	if err := s.ledger.Reserve(reservation, coupon.MaxRedemptions, coupon.MaxPerCustomer); err != nil {
+		return nil, err
+	}
+	if err := s.spend(reservation.CampaignID, reservation.DiscountAmount, now); err != nil {
+		return nil, errors.Join(err, s.ledger.Release(reservation.ID))
+	}
+	if err := s.pauseExhausted(reservation.CampaignID); err != nil {
+		return nil, err
+	}
+
+	result.ReservationID = reservation.ID
+	result.ReservedUntil = &reservation.ExpiresAt
+
+	return result, nil
+}
+
+// CommitReservation turns a reservation into a redemption. Its discount was
+// charged to the campaign when it was reserved; a reservation that expired
+// in the meantime gives it back instead. Only the customer who made the
+// reservation can commit it.
+func (s *Service) CommitReservation(id string, customer Customer) error {
+	reservation, err := s.findReservation(id, customer)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.ledger.Commit(id, s.now()); err != nil {
+		if errors.Is(err, ErrReservationExpired) {
+			return errors.Join(err, s.refundReservation(*reservation))
+		}
+		return err
+	}
+	return nil
+}
+
+// ReleaseReservation cancels a reservation, frees its redemption and gives
+// its discount back to the campaign. Only the customer who made the
+// reservation can release it.
+func (s *Service) ReleaseReservation(id string, customer Customer) error {
+	reservation, err := s.findReservation(id, customer)
+	if err != nil {
+		return err
+	}
+	if err := s.ledger.Release(id); err != nil {
+		return err
+	}
+	return s.refundReservation(*reservation)
+}
+
+// refundExpired gives the discount of every reservation that expired by
+// now back to its campaign.
+func (s *Service) refundExpired(now time.Time) error {
+	expired, err := s.ledger.Expired(now)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, reservation := range expired {
+		errs = append(errs, s.refundReservation(reservation))
+	}
+	return errors.Join(errs...)
+}
+
+// refundReservation gives the discount charged when the reservation was
+// made back to its campaign.
+func (s *Service) refundReservation(reservation Reservation) error {
+	return s.refund(reservation.CampaignID, reservation.DiscountAmount, reservation.ReservedAt)
+}
+
+// findReservation returns the reservation if it belongs to the customer.
+// Reservations of other customers are reported as not found, so their IDs
+// cannot be probed.
+func (s *Service) findReservation(id string, customer Customer) (*Reservation, error) {
+	reservation, err := s.ledger.FindReservation(id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.CustomerID != customer.ID {
+		return nil, ErrReservationNotFound
+	}
+	return reservation, nil
+}
@@ -0,0 +1,141 @@
+package rule
+
+import (
+	"fmt"
+	"slices"
+)
+
+// node is a type-checked expression of a compiled rule
+type node interface {
+	typ() Type
+	eval(Env) (any, error)
+}
+
+type literal struct {
+	value any
+	kind  Type
+}
+
+func (l *literal) typ() Type { return l.kind }
+
+func (l *literal) eval(Env) (any, error) { return l.value, nil }
+
+type variable struct {
+	name string
+	kind Type
+}
+
+func (v *variable) typ() Type { return v.kind }
+
+func (v *variable) eval(env Env) (any, error) {
+	value, ok := env[v.name]
+	if !ok {
+		return nil, fmt.Errorf("variable %q is not set", v.name)
+	}
+
+	valid := false
+	switch v.kind {
+	case Bool:
+		_, valid = value.(bool)
+	case Number:
+		_, valid = value.(float64)
+	case String:
+		_, valid = value.(string)
+	case List:
+		_, valid = value.([]string)
+	}
+	if !valid {
+		return nil, fmt.Errorf("variable %q is %T, want %s", v.name, value, v.kind)
+	}
+	return value, nil
+}
+
+type negation struct {
+	operand node
+}
+
+func (n *negation) typ() Type { return Bool }
+
+func (n *negation) eval(env Env) (any, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return !value.(bool), nil
+}
+
+type binary struct {
+	op          string
+	left, right node
+}
+
+// newBinary checks the operand types of the operator.
+func newBinary(op string, left, right node, pos int) (node, error) {
+	l, r := left.typ(), right.typ()
+
+	valid := false
+	switch op {
+	case "&&", "||":
+		valid = l == Bool && r == Bool
+	case "==", "!=":
+		valid = l == r && l != List
+	case "<", "<=", ">", ">=":
+		valid = l == Number && r == Number
+	case "in":
+		valid = l == String && r == List
+	case "contains":
+		valid = l == List && r == String
+	}
+	if !valid {
+		return nil, fmt.Errorf("position %d: cannot apply %q to %s and %s", pos, op, l, r)
+	}
+	return &binary{op: op, left: left, right: right}, nil
+}
+
+func (b *binary) typ() Type { return Bool }
+
+func (b *binary) eval(env Env) (any, error) {
+	left, err := b.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || short-circuit like in Go
+	switch b.op {
+	case "&&":
+		if !left.(bool) {
+			return false, nil
+		}
+		return b.right.eval(env)
+	case "||":
+		if left.(bool) {
+			return true, nil
+		}
+		return b.right.eval(env)
+	}
+
+	right, err := b.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	case "<":
+		return left.(float64) < right.(float64), nil
+	case "<=":
+		return left.(float64) <= right.(float64), nil
+	case ">":
+		return left.(float64) > right.(float64), nil
+	case ">=":
+		return left.(float64) >= right.(float64), nil
+	case "in":
+		return slices.Contains(right.([]string), left.(string)), nil
+	case "contains":
+		return slices.Contains(left.([]string), right.(string)), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", b.op)
+}
@@ -0,0 +1,213 @@
+package rule
+
+import "fmt"
+
+type parser struct {
+	tokens    []token
+	pos       int
+	depth     int
+	schema    Schema
+	variables map[string]bool
+}
+
+// comparisons are the non-associative operators between operands.
+var comparisons = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"in": true, "contains": true,
+}
+
+func (p *parser) parse() (node, error) {
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("position %d: unexpected %q", tok.pos, tok.text)
+	}
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		tok := p.peek()
+		if tok.kind == tokenEOF {
+			return fmt.Errorf("position %d: expected %q, got end of rule", tok.pos, op)
+		}
+		return fmt.Errorf("position %d: expected %q, got %q", tok.pos, op, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		pos := p.peek().pos
+		if !p.accept("||") {
+			return left, nil
+		}
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newBinary("||", left, right, pos); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		pos := p.peek().pos
+		if !p.accept("&&") {
+			return left, nil
+		}
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newBinary("&&", left, right, pos); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) not() (node, error) {
+	pos := p.peek().pos
+	if !p.accept("!") {
+		return p.comparison()
+	}
+
+	if err := p.enter(pos); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	operand, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	if operand.typ() != Bool {
+		return nil, fmt.Errorf("position %d: cannot negate %s", pos, operand.typ())
+	}
+	return &negation{operand: operand}, nil
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if tok.kind != tokenOperator || !comparisons[tok.text] {
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return newBinary(tok.text, left, right, tok.pos)
+}
+
+func (p *parser) operand() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		return &literal{value: tok.value, kind: Number}, nil
+	case tokenString:
+		return &literal{value: tok.value, kind: String}, nil
+	case tokenIdent:
+		kind, ok := p.schema[tok.text]
+		if !ok {
+			return nil, fmt.Errorf("position %d: unknown variable %q", tok.pos, tok.text)
+		}
+		p.variables[tok.text] = true
+		return &variable{name: tok.text, kind: kind}, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("position %d: unexpected end of rule", tok.pos)
+	}
+
+	switch tok.text {
+	case "true", "false":
+		return &literal{value: tok.text == "true", kind: Bool}, nil
+	case "(":
+		if err := p.enter(tok.pos); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case "[":
+		return p.list()
+	}
+	return nil, fmt.Errorf("position %d: unexpected %q", tok.pos, tok.text)
+}
+
+// list parses a list literal after its opening bracket.
+func (p *parser) list() (node, error) {
+	items := []string{}
+	if p.accept("]") {
+		return &literal{value: items, kind: List}, nil
+	}
+	for {
+		tok := p.next()
+		if tok.kind != tokenString {
+			return nil, fmt.Errorf("position %d: list items must be strings", tok.pos)
+		}
+		items = append(items, tok.value.(string))
+		if p.accept("]") {
+			return &literal{value: items, kind: List}, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) enter(pos int) error {
+	p.depth++
+	if p.depth > maxDepth {
+		return fmt.Errorf("position %d: rule nested deeper than %d levels", pos, maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
@@ -0,0 +1,126 @@
+// Package rule implements the small expression language used for coupon
+// eligibility rules, for example
+//
+//	customer.new && basket.categories contains "shoes"
+//	customer.role == "vip" || basket.value >= 100
+//
+// Rules are compiled against a Schema that declares the variables they may
+// read and their types, so unknown variables and type errors are reported
+// when the rule is written rather than when it is evaluated. The language
+// has no function calls, assignments or loops: a compiled rule can only
+// read the variables it is given and always terminates.
+//
+// Operators, from lowest to highest precedence:
+//
+//	||  or
+//	&&  and
+//	==  !=  <  <=  >  >=  in  contains
+//	!   not
+//
+// Literals are numbers, quoted strings, true, false and lists of strings
+// such as ["vip", "staff"].
+package rule
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// MaxLength is the longest rule source accepted by Compile.
+const MaxLength = 1024
+
+// maxDepth bounds the nesting of a rule so parsing cannot exhaust the stack.
+const maxDepth = 32
+
+// Type is the type of a rule variable or expression
+type Type int
+
+const (
+	Bool Type = iota + 1
+	Number
+	String
+	List
+)
+
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case List:
+		return "list"
+	default:
+		return "unknown"
+	}
+}
+
+// Schema declares the variables a rule may read
+type Schema map[string]Type
+
+// Env holds the variable values a rule is evaluated with. Values must be
+// bool, float64, string or []string according to the schema.
+type Env map[string]any
+
+// Rule is a compiled eligibility rule
+type Rule struct {
+	src       string
+	root      node
+	variables []string
+}
+
+// Compile parses the rule and checks it against the schema. The rule must
+// evaluate to a bool.
+func Compile(src string, schema Schema) (*Rule, error) {
+	if len(src) > MaxLength {
+		return nil, fmt.Errorf("rule longer than %d characters", MaxLength)
+	}
+
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, schema: schema, variables: make(map[string]bool)}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if root.typ() != Bool {
+		return nil, fmt.Errorf("rule must be a condition, got %s", root.typ())
+	}
+
+	variables := make([]string, 0, len(p.variables))
+	for name := range p.variables {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+
+	return &Rule{src: src, root: root, variables: variables}, nil
+}
+
+// Eval reports whether the rule holds for the environment.
+func (r *Rule) Eval(env Env) (bool, error) {
+	value, err := r.root.eval(env)
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+// Uses reports whether the rule reads the variable.
+func (r *Rule) Uses(name string) bool {
+	return slices.Contains(r.variables, name)
+}
+
+// Variables returns the sorted names of the variables the rule reads.
+func (r *Rule) Variables() []string {
+	return slices.Clone(r.variables)
+}
+
+func (r *Rule) String() string {
+	return r.src
+}
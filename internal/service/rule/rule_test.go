@@ -0,0 +1,109 @@
+package rule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSchema = Schema{
+	"customer.new":      Bool,
+	"customer.role":     String,
+	"basket.value":      Number,
+	"basket.categories": List,
+}
+
+var testEnv = Env{
+	"customer.new":      true,
+	"customer.role":     "vip",
+	"basket.value":      120.0,
+	"basket.categories": []string{"shoes", "socks"},
+}
+
+func TestCompile_Eval(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		expect bool
+	}{
+		{name: "bool variable", rule: "customer.new", expect: true},
+		{name: "string equality", rule: `customer.role == "vip"`, expect: true},
+		{name: "single quotes", rule: `customer.role != 'vip'`, expect: false},
+		{name: "number comparison", rule: "basket.value >= 100", expect: true},
+		{name: "decimal number", rule: "basket.value < 119.99", expect: false},
+		{name: "list contains", rule: `basket.categories contains "shoes"`, expect: true},
+		{name: "in list literal", rule: `customer.role in ["staff", "vip"]`, expect: true},
+		{name: "empty list literal", rule: `customer.role in []`, expect: false},
+		{name: "and", rule: `customer.new && basket.categories contains "hats"`, expect: false},
+		{name: "or", rule: `!customer.new || basket.value > 100`, expect: true},
+		{name: "word operators", rule: `not customer.new or customer.role == "vip" and basket.value > 1`, expect: true},
+		{name: "precedence", rule: `true || false && false`, expect: true},
+		{name: "parentheses", rule: `(true || false) && false`, expect: false},
+		{name: "double negation", rule: "!!customer.new", expect: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Compile(tt.rule, testSchema)
+			assert.NoError(t, err)
+
+			result, err := r.Eval(testEnv)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, result)
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        string
+		expectedErr string
+	}{
+		{name: "empty rule", rule: "", expectedErr: "unexpected end of rule"},
+		{name: "unknown variable", rule: "customer.age > 18", expectedErr: `unknown variable "customer.age"`},
+		{name: "not a condition", rule: "basket.value", expectedErr: "rule must be a condition"},
+		{name: "type mismatch", rule: `basket.value == "100"`, expectedErr: `cannot apply "==" to number and string`},
+		{name: "ordering strings", rule: `customer.role > "a"`, expectedErr: `cannot apply ">"`},
+		{name: "negating number", rule: "!basket.value", expectedErr: "cannot negate number"},
+		{name: "chained comparison", rule: "1 < 2 < 3", expectedErr: `unexpected "<"`},
+		{name: "unterminated string", rule: `customer.role == "vip`, expectedErr: "unterminated string"},
+		{name: "missing parenthesis", rule: "(customer.new", expectedErr: `expected ")"`},
+		{name: "unexpected character", rule: "customer.new; true", expectedErr: "unexpected character"},
+		{name: "numbers in list", rule: "customer.role in [1]", expectedErr: "list items must be strings"},
+		{name: "function call", rule: "exit(1)", expectedErr: `unknown variable "exit"`},
+		{name: "too deep", rule: strings.Repeat("(", 40) + "true" + strings.Repeat(")", 40), expectedErr: "nested deeper"},
+		{name: "too long", rule: strings.Repeat(" ", MaxLength) + "true", expectedErr: "rule longer than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Compile(tt.rule, testSchema)
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErr)
+			assert.Nil(t, r)
+		})
+	}
+}
+
+func TestRule_Eval_MissingVariable(t *testing.T) {
+	r, err := Compile("customer.new", testSchema)
+	assert.NoError(t, err)
+
+	_, err = r.Eval(Env{})
+	assert.ErrorContains(t, err, `variable "customer.new" is not set`)
+
+	_, err = r.Eval(Env{"customer.new": "yes"})
+	assert.ErrorContains(t, err, `variable "customer.new" is string, want bool`)
+}
+
+func TestRule_Variables(t *testing.T) {
+	r, err := Compile(`customer.role == "vip" || customer.new && customer.new`, testSchema)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"customer.new", "customer.role"}, r.Variables())
+	assert.True(t, r.Uses("customer.new"))
+	assert.False(t, r.Uses("basket.value"))
+}
@@ -1,62 +1,424 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	. "reviewsch/internal/service/entity"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// Repository stores coupons. Save and SaveBatch only add new coupons and
+// must return ErrCouponExists if a code is already taken; SaveBatch stores
+// nothing in that case. SetStatus must change the status atomically
+// and only if it still is from, returning ErrInvalidTransition otherwise.
+// Update and Delete must only succeed while the stored coupon has the given
+// version, returning ErrVersionConflict otherwise. SetStatus and Update
+// increment the version. Query returns up to query.Limit coupons matching
+// the query in its order. FindActive returns the active public coupons
+// whose time window contains the given time; it is called for every
+// applicable-coupon lookup and must use an index rather than read every
+// coupon. FindAssigned returns the personal coupons assigned to a customer,
+// whatever their status, likewise through an index.
 type Repository interface {
 	FindByCode(string) (*Coupon, error)
 	Save(Coupon) error
+	SaveBatch([]Coupon) error
+	SetStatus(code string, from, to CouponStatus) (*Coupon, error)
+	Update(coupon Coupon, version int) (*Coupon, error)
+	Delete(code string, version int) error
+	Query(query CouponQuery) ([]Coupon, error)
+	FindActive(at time.Time) ([]Coupon, error)
+	FindAssigned(customerID string) ([]Coupon, error)
 }
 
+// Ledger records coupon redemptions. Redeem must check the limits and
+// record the redemption atomically, returning ErrRedemptionLimitReached or
+// ErrCustomerLimitReached when a limit would be exceeded. A zero limit
+// means unlimited. Usage counts what Redeem checks against the limits: the
+// redemptions of a code, in total and by the customer.
+//
+// Reserve holds a redemption until it is committed, released or expires.
+// Active reservations count towards the limits exactly like redemptions.
+// Expired reservations stay in the ledger until Expired removes and returns
+// them, so the campaign discount they hold can be given back.
+//
+// CustomerRedemptions counts the committed redemptions of a customer across
+// all coupons.
+type Ledger interface {
+	Redeem(redemption Redemption, maxTotal, maxPerCustomer int) error
+	Reserve(reservation Reservation, maxTotal, maxPerCustomer int) error
+	Commit(id string, at time.Time) (*Redemption, error)
+	Release(id string) error
+	Expired(at time.Time) ([]Reservation, error)
+	FindReservation(id string) (*Reservation, error)
+	CustomerRedemptions(customerID string) (int, error)
+	Usage(code, customerID string, at time.Time) (total, perCustomer int, err error)
+}
+
+// CampaignRepository stores campaigns. Spend must check the budgets and
+// record the spend atomically, returning ErrCampaignPaused or
+// ErrCampaignBudgetExhausted when the campaign cannot fund the discount,
+// and pause the campaign once a budget is used up. Refund gives back a
+// spend whose redemption could not be recorded. Amounts are in the campaign
+// currency.
+type CampaignRepository interface {
+	FindByID(string) (*Campaign, error)
+	Save(Campaign) error
+	Spend(id string, amount Money) error
+	Refund(id string, amount Money) error
+}
+
+// RateRepository stores versions of the exchange-rate table. Save assigns
+// the next version number. Active returns the table with the latest start
+// time not after the given time, and ErrRateNotFound if there is none.
+type RateRepository interface {
+	Save(RateTable) (*RateTable, error)
+	Active(at time.Time) (*RateTable, error)
+	FindByVersion(int) (*RateTable, error)
+}
+
+// AuditLog stores the audit entries of coupon mutations. Append stores all
+// entries or none; entries are never changed. Query returns up to
+// query.Limit matching entries, newest first.
+type AuditLog interface {
+	Append(entries ...AuditEntry) error
+	Query(query AuditQuery) ([]AuditEntry, error)
+}
+
+// CouponHistory keeps every stored version of the coupons. Append stores
+// all versions or none; versions are never changed, and a deleted coupon
+// keeps its history. Versions returns the versions recorded for a code in
+// the order they were appended. FindVersion returns ErrCouponVersionNotFound
+// if no version has the ID.
+type CouponHistory interface {
+	Append(versions ...CouponVersion) error
+	Versions(code string) ([]CouponVersion, error)
+	FindVersion(id string) (*CouponVersion, error)
+}
+
+// DefaultReservationTTL is how long a reservation holds a coupon before it
+// expires.
+const DefaultReservationTTL = 15 * time.Minute
+
 type Service struct {
-	repo Repository
+	repo           Repository
+	ledger         Ledger
+	campaigns      CampaignRepository
+	rates          RateRepository
+	audit          AuditLog
+	history        CouponHistory
+	now            func() time.Time
+	reservationTTL time.Duration
 }
 
-func New(repo Repository) *Service {
+func New(repo Repository, ledger Ledger, campaigns CampaignRepository, rates RateRepository, audit AuditLog, history CouponHistory) *Service {
 	return &Service{
-		repo: repo,
+		repo:           repo,
+		ledger:         ledger,
+		campaigns:      campaigns,
+		rates:          rates,
+		audit:          audit,
+		history:        history,
+		now:            time.Now,
+		reservationTTL: DefaultReservationTTL,
 	}
 }
 
-func (s *Service) ApplyCoupon(basket Basket, code string) (*Basket, error) {
-	if code == "" {
-		return nil, fmt.Errorf("empty coupon code")
+func (s *Service) ApplyCoupon(basket Basket, code string, customer Customer) (*Basket, error) {
+	now := s.now()
+	if err := s.refundExpired(now); err != nil {
+		return nil, err
 	}
-
-	coupon, err := s.repo.FindByCode(code)
+	coupon, result, err := s.evaluate(basket, code, customer, now, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.spend(coupon.CampaignID, result.DiscountAmount, now); err != nil {
+		return nil, err
+	}
+
+	redemption := Redemption{
+		ID:              uuid.NewString(),
+		Code:            coupon.Code,
+		CampaignID:      coupon.CampaignID,
+		CustomerID:      customer.ID,
+		DiscountAmount:  result.DiscountAmount,
+		RedeemedAt:      now,
+		CouponVersionID: result.CouponVersionID,
+	}
+	if err := s.ledger.Redeem(redemption, coupon.MaxRedemptions, coupon.MaxPerCustomer); err != nil {
+		return nil, errors.Join(err, s.refund(coupon.CampaignID, result.DiscountAmount, now))
+	}
+	if err := s.pauseExhausted(coupon.CampaignID); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// evaluate checks that the coupon can be applied to the basket at the given
+// time and returns the coupon together with the discounted basket. It has
+// no side effects; callers decide how the use of the coupon is recorded.
+// Every check is recorded with the tracer, which may be nil.
+func (s *Service) evaluate(basket Basket, code string, customer Customer, now time.Time, t *tracer) (*Coupon, *Basket, error) {
+	coupon, err := s.findForApply(code)
+	if err := t.check(CheckExists, err, code, ""); err != nil {
+		return nil, nil, err
+	}
+
+	if err := t.check(CheckActive, checkStatus(coupon), string(coupon.Status), string(CouponActive)); err != nil {
+		return nil, nil, err
+	}
+	if err := t.check(CheckTimeWindow, checkTimeWindow(coupon, now), now.Format(time.RFC3339), timeWindow(coupon)); err != nil {
+		return nil, nil, err
+	}
+	if err := t.check(CheckCustomer, checkAssigned(coupon, customer), customer.ID, assignment(coupon)); err != nil {
+		return nil, nil, err
+	}
+
 	result := &basket
-	if result.Value <= 0 {
-		return nil, fmt.Errorf("invalid basket value")
+	err = normalizeBasket(result)
+	if err == nil && result.Value.Amount <= 0 {
+		err = fmt.Errorf("%w: invalid basket value", ErrInvalidRequest)
+	}
+	if err := t.check(CheckBasket, err, amount(result.Value), ""); err != nil {
+		return nil, nil, err
+	}
+
+	from := coupon.Currency
+	coupon, rate, err := s.localTerms(coupon, result.Currency, now)
+	if err := t.check(CheckCurrency, err, result.Currency, from); err != nil {
+		return nil, nil, err
+	}
+
+	if result.Value.Amount < coupon.MinBasketValue.Amount {
+		err = fmt.Errorf("%w: got %s, want minimum %s",
+			ErrBelowMinBasketValue, result.Value, coupon.MinBasketValue)
+	}
+	if err := t.check(CheckMinBasket, err, amount(result.Value), ">= "+amount(coupon.MinBasketValue)); err != nil {
+		return nil, nil, err
+	}
+
+	usage, err := s.checkLimits(coupon, customer, now)
+	if err := t.check(CheckLimits, err, usage, limits(coupon)); err != nil {
+		return nil, nil, err
+	}
+
+	values, err := s.checkRule(coupon, result, customer)
+	if err := t.check(CheckRule, err, values, coupon.Rule); err != nil {
+		return nil, nil, err
+	}
+
+	discount, err := applyDiscount(coupon, result)
+	if err == nil && discount.IsZero() {
+		err = fmt.Errorf("%w: coupon takes nothing off the basket", ErrNoEligibleItems)
+	}
+	if err := t.check(CheckDiscount, err, amount(discount), ""); err != nil {
+		return nil, nil, err
+	}
+
+	if err := t.check(CheckCampaign, s.checkCampaign(coupon.CampaignID, discount, now), coupon.CampaignID, ""); err != nil {
+		return nil, nil, err
 	}
 
 	result.AppliedDiscount = coupon.Discount
 	result.ApplicationSuccessful = true
 	result.CouponCode = code
+	result.CouponVersionID = coupon.VersionID()
+	result.OriginalValue = result.Value
+	result.DiscountAmount = discount
+	result.FinalValue = result.Value.Add(result.ShippingCost).Sub(discount)
+	result.ExchangeRate = rate
 
-	return result, nil
+	return coupon, result, nil
 }
 
-func (s *Service) CreateCoupon(discount int, code string, minBasketValue float64) error {
+// findForApply looks up the coupon a basket is applied with.
+func (s *Service) findForApply(code string) (*Coupon, error) {
 	if code == "" {
-		return fmt.Errorf("empty coupon code")
+		return nil, fmt.Errorf("%w: empty coupon code", ErrInvalidRequest)
+	}
+	return s.repo.FindByCode(code)
+}
+
+// checkTimeWindow verifies that the coupon can be used at the given time.
+func checkTimeWindow(coupon *Coupon, now time.Time) error {
+	if !coupon.StartsAt.IsZero() && now.Before(coupon.StartsAt) {
+		return fmt.Errorf("%w: starts at %s", ErrCouponNotYetActive, coupon.StartsAt.Format(time.RFC3339))
 	}
+	if !coupon.ExpiresAt.IsZero() && !now.Before(coupon.ExpiresAt) {
+		return fmt.Errorf("%w: expired at %s", ErrCouponExpired, coupon.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
 
-	coupon := Coupon{
-		ID:             uuid.NewString(),
-		Discount:       discount,
-		Code:           code,
-		MinBasketValue: minBasketValue,
+// checkAssigned verifies that the customer may apply a personal coupon.
+func checkAssigned(coupon *Coupon, customer Customer) error {
+	if !coupon.Personal() {
+		return nil
+	}
+	if customer.ID == "" {
+		return ErrCustomerRequired
+	}
+	if !coupon.AssignedTo(customer.ID) {
+		return ErrCouponNotAssigned
 	}
+	return nil
+}
 
-	return s.repo.Save(coupon)
+// checkLimits verifies that the customer can still redeem the coupon and
+// describes its usage. The ledger checks the limits again when the
+// redemption is recorded, since other redemptions may happen in between.
+func (s *Service) checkLimits(coupon *Coupon, customer Customer, now time.Time) (string, error) {
+	if coupon.MaxPerCustomer > 0 && customer.ID == "" {
+		return "", ErrCustomerRequired
+	}
+	if coupon.MaxRedemptions <= 0 && coupon.MaxPerCustomer <= 0 {
+		return "", nil
+	}
+
+	total, perCustomer, err := s.ledger.Usage(coupon.Code, customer.ID, now)
+	if err != nil {
+		return "", err
+	}
+	usage := fmt.Sprintf("%d redeemed, %d by customer", total, perCustomer)
+	if coupon.MaxRedemptions > 0 && total >= coupon.MaxRedemptions {
+		return usage, ErrRedemptionLimitReached
+	}
+	if coupon.MaxPerCustomer > 0 && perCustomer >= coupon.MaxPerCustomer {
+		return usage, ErrCustomerLimitReached
+	}
+	return usage, nil
+}
+
+// CreateCoupon stores a new coupon and records its creation by the actor.
+func (s *Service) CreateCoupon(coupon Coupon, actor Actor) error {
+	if coupon.Code == "" {
+		return fmt.Errorf("%w: empty coupon code", ErrInvalidRequest)
+	}
+	if err := prepareTerms(&coupon); err != nil {
+		return err
+	}
+	if err := s.checkCouponCampaign(coupon); err != nil {
+		return err
+	}
+
+	coupon.ID = uuid.NewString()
+	coupon.Version = 1
+
+	if err := s.repo.Save(coupon); err != nil {
+		return err
+	}
+	return s.record(actor, AuditCreate, nil, &coupon)
+}
+
+// GetCoupon returns a single coupon with its current version.
+func (s *Service) GetCoupon(code string) (*Coupon, error) {
+	if code == "" {
+		return nil, fmt.Errorf("%w: empty coupon code", ErrInvalidRequest)
+	}
+	return s.repo.FindByCode(code)
+}
+
+// UpdateCoupon replaces the terms of a coupon if it is still at the given
+// version. The code, ID and lifecycle status are kept; the status changes
+// through the lifecycle operations only.
+func (s *Service) UpdateCoupon(code string, coupon Coupon, version int, actor Actor) (*Coupon, error) {
+	current, err := s.GetCoupon(code)
+	if err != nil {
+		return nil, err
+	}
+	if current.Version != version {
+		return nil, fmt.Errorf("%w: coupon is at version %d, got %d", ErrVersionConflict, current.Version, version)
+	}
+
+	coupon.Status = ""
+	if err := prepareTerms(&coupon); err != nil {
+		return nil, err
+	}
+	coupon.ID, coupon.Code, coupon.Status = current.ID, current.Code, current.Status
+	if err := s.checkCouponCampaign(coupon); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.Update(coupon, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(actor, AuditUpdate, current, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteCoupon removes a coupon if it is still at the given version. Its
+// redemptions stay in the ledger; ArchiveCoupon keeps the coupon as well.
+func (s *Service) DeleteCoupon(code string, version int, actor Actor) error {
+	current, err := s.GetCoupon(code)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(code, version); err != nil {
+		return err
+	}
+	return s.record(actor, AuditDelete, current, nil)
+}
+
+// prepareTerms fills in defaults and validates the discount settings of a
+// coupon, independently of its code.
+func prepareTerms(coupon *Coupon) error {
+	if coupon.Type == "" {
+		coupon.Type = DiscountPercentage
+	}
+	switch coupon.Status {
+	case "":
+		coupon.Status = CouponActive
+	case CouponDraft, CouponActive:
+	default:
+		return fmt.Errorf("%w: new coupons must be draft or active, got %q", ErrInvalidCoupon, coupon.Status)
+	}
+	if coupon.Currency == "" {
+		coupon.Currency = DefaultCurrency
+	}
+	if _, err := LookupCurrency(coupon.Currency); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCoupon, err)
+	}
+	minimum, err := coupon.MinBasketValue.In(coupon.Currency)
+	if err != nil {
+		return fmt.Errorf("%w: minimum basket value: %v", ErrInvalidCoupon, err)
+	}
+	coupon.MinBasketValue = minimum
+	if err := validateAmounts(*coupon); err != nil {
+		return err
+	}
+
+	if err := validateDiscount(*coupon); err != nil {
+		return err
+	}
+	if !coupon.StartsAt.IsZero() && !coupon.ExpiresAt.IsZero() && !coupon.ExpiresAt.After(coupon.StartsAt) {
+		return fmt.Errorf("%w: expiry must be after start", ErrInvalidCoupon)
+	}
+	if coupon.MaxRedemptions < 0 || coupon.MaxPerCustomer < 0 {
+		return fmt.Errorf("%w: redemption limits must not be negative", ErrInvalidCoupon)
+	}
+	if coupon.Rule != "" {
+		if _, err := compileRule(coupon.Rule); err != nil {
+			return err
+		}
+	}
+	if slices.Contains(coupon.CustomerIDs, "") {
+		return fmt.Errorf("%w: empty customer id", ErrInvalidCoupon)
+	}
+	if coupon.Personal() {
+		customers := slices.Clone(coupon.CustomerIDs)
+		slices.Sort(customers)
+		coupon.CustomerIDs = slices.Compact(customers)
+	}
+	return nil
 }
 
 func (s *Service) GetCoupons(codes []string) ([]Coupon, error) {
@@ -2,16 +2,27 @@ package service
 
 import (
 	"fmt"
+	"math"
 	. "reviewsch/internal/service/entity"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// eur converts a decimal amount into money in the default currency
+func eur(amount float64) Money {
+	return Money{Amount: int64(math.Round(amount * 100)), Currency: "EUR"}
+}
+
 // mockRepository is a mock implementation of Repository interface
 type mockRepository struct {
-	coupons map[string]*Coupon
-	err     error
+	coupons   map[string]*Coupon
+	batches   int
+	failBatch int
+	err       error
 }
 
 func newMockRepository() *mockRepository {
@@ -26,7 +37,7 @@ func (m *mockRepository) FindByCode(code string) (*Coupon, error) {
 	}
 	coupon, exists := m.coupons[code]
 	if !exists {
-		return nil, fmt.Errorf("coupon not found")
+		return nil, ErrCouponNotFound
 	}
 	return coupon, nil
 }
@@ -35,206 +46,2982 @@ func (m *mockRepository) Save(coupon Coupon) error {
 	if m.err != nil {
 		return m.err
 	}
+	if _, exists := m.coupons[coupon.Code]; exists {
+		return ErrCouponExists
+	}
+	m.coupons[coupon.Code] = &coupon
+	return nil
+}
+
+func (m *mockRepository) SaveBatch(coupons []Coupon) error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.batches+1 == m.failBatch {
+		return fmt.Errorf("database error")
+	}
+	m.batches++
+	for _, coupon := range coupons {
+		m.coupons[coupon.Code] = &coupon
+	}
+	return nil
+}
+
+func (m *mockRepository) SetStatus(code string, from, to CouponStatus) (*Coupon, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	coupon, exists := m.coupons[code]
+	if !exists {
+		return nil, ErrCouponNotFound
+	}
+	if coupon.Status != from {
+		return nil, ErrInvalidTransition
+	}
+	updated := *coupon
+	updated.Status = to
+	updated.Version++
+	m.coupons[code] = &updated
+	result := updated
+	return &result, nil
+}
+
+func (m *mockRepository) Update(coupon Coupon, version int) (*Coupon, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	current, exists := m.coupons[coupon.Code]
+	if !exists {
+		return nil, ErrCouponNotFound
+	}
+	if current.Version != version {
+		return nil, ErrVersionConflict
+	}
+	coupon.Version = version + 1
 	m.coupons[coupon.Code] = &coupon
+	updated := coupon
+	return &updated, nil
+}
+
+func (m *mockRepository) Delete(code string, version int) error {
+	if m.err != nil {
+		return m.err
+	}
+	current, exists := m.coupons[code]
+	if !exists {
+		return ErrCouponNotFound
+	}
+	if current.Version != version {
+		return ErrVersionConflict
+	}
+	delete(m.coupons, code)
+	return nil
+}
+
+func (m *mockRepository) Query(query CouponQuery) ([]Coupon, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var coupons []Coupon
+	for _, coupon := range m.coupons {
+		if query.Matches(*coupon) {
+			coupons = append(coupons, *coupon)
+		}
+	}
+	sort.Slice(coupons, func(i, j int) bool {
+		return query.Compare(CursorOf(coupons[i]), CursorOf(coupons[j])) < 0
+	})
+	if len(coupons) > query.Limit {
+		coupons = coupons[:query.Limit]
+	}
+	return coupons, nil
+}
+
+func (m *mockRepository) FindActive(at time.Time) ([]Coupon, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var coupons []Coupon
+	for _, coupon := range m.coupons {
+		if coupon.Status == CouponActive && coupon.InWindow(at) && !coupon.Personal() {
+			coupons = append(coupons, *coupon)
+		}
+	}
+	return coupons, nil
+}
+
+func (m *mockRepository) FindAssigned(customerID string) ([]Coupon, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var coupons []Coupon
+	for _, coupon := range m.coupons {
+		if coupon.Personal() && coupon.AssignedTo(customerID) {
+			coupons = append(coupons, *coupon)
+		}
+	}
+	return coupons, nil
+}
+
+// mockLedger is a mock implementation of Ledger interface
+type mockLedger struct {
+	redemptions  []Redemption
+	reservations map[string]Reservation
+	err          error
+	codeErr      map[string]error
+}
+
+func newMockLedger() *mockLedger {
+	return &mockLedger{
+		reservations: make(map[string]Reservation),
+	}
+}
+
+func (m *mockLedger) Redeem(redemption Redemption, maxTotal, maxPerCustomer int) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.redemptions = append(m.redemptions, redemption)
+	return nil
+}
+
+func (m *mockLedger) Reserve(reservation Reservation, maxTotal, maxPerCustomer int) error {
+	if m.err != nil {
+		return m.err
+	}
+	if err := m.codeErr[reservation.Code]; err != nil {
+		return err
+	}
+	m.reservations[reservation.ID] = reservation
+	return nil
+}
+
+func (m *mockLedger) Commit(id string, at time.Time) (*Redemption, error) {
+	reservation, exists := m.reservations[id]
+	if !exists {
+		return nil, ErrReservationNotFound
+	}
+	delete(m.reservations, id)
+	if !at.Before(reservation.ExpiresAt) {
+		return nil, ErrReservationExpired
+	}
+	redemption := Redemption{
+		ID:              id,
+		Code:            reservation.Code,
+		CampaignID:      reservation.CampaignID,
+		CustomerID:      reservation.CustomerID,
+		DiscountAmount:  reservation.DiscountAmount,
+		RedeemedAt:      at,
+		CouponVersionID: reservation.CouponVersionID,
+	}
+	m.redemptions = append(m.redemptions, redemption)
+	return &redemption, nil
+}
+
+func (m *mockLedger) FindReservation(id string) (*Reservation, error) {
+	reservation, exists := m.reservations[id]
+	if !exists {
+		return nil, ErrReservationNotFound
+	}
+	return &reservation, nil
+}
+
+func (m *mockLedger) CustomerRedemptions(customerID string) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	count := 0
+	for _, redemption := range m.redemptions {
+		if redemption.CustomerID == customerID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockLedger) Usage(code, customerID string, at time.Time) (int, int, error) {
+	total, customer := 0, 0
+	for _, redemption := range m.redemptions {
+		if redemption.Code == code {
+			total++
+			if redemption.CustomerID == customerID {
+				customer++
+			}
+		}
+	}
+	return total, customer, nil
+}
+
+func (m *mockLedger) Expired(at time.Time) ([]Reservation, error) {
+	var expired []Reservation
+	for id, reservation := range m.reservations {
+		if !at.Before(reservation.ExpiresAt) {
+			expired = append(expired, reservation)
+			delete(m.reservations, id)
+		}
+	}
+	return expired, nil
+}
+
+func (m *mockLedger) Release(id string) error {
+	if _, exists := m.reservations[id]; !exists {
+		return ErrReservationNotFound
+	}
+	delete(m.reservations, id)
+	return nil
+}
+
+// mockCampaignRepository is a mock implementation of CampaignRepository interface
+type mockCampaignRepository struct {
+	campaigns map[string]*Campaign
+}
+
+func newMockCampaignRepository() *mockCampaignRepository {
+	return &mockCampaignRepository{
+		campaigns: make(map[string]*Campaign),
+	}
+}
+
+func (m *mockCampaignRepository) FindByID(id string) (*Campaign, error) {
+	campaign, exists := m.campaigns[id]
+	if !exists {
+		return nil, ErrCampaignNotFound
+	}
+	found := *campaign
+	return &found, nil
+}
+
+func (m *mockCampaignRepository) Save(campaign Campaign) error {
+	m.campaigns[campaign.ID] = &campaign
+	return nil
+}
+
+func (m *mockCampaignRepository) Spend(id string, amount Money) error {
+	campaign, exists := m.campaigns[id]
+	if !exists {
+		return ErrCampaignNotFound
+	}
+	if campaign.Paused {
+		return ErrCampaignPaused
+	}
+	campaign.DiscountSpent = campaign.DiscountSpent.Add(amount)
+	campaign.Redemptions++
+	campaign.Paused = campaign.Exhausted()
+	return nil
+}
+
+func (m *mockCampaignRepository) Refund(id string, amount Money) error {
+	campaign, exists := m.campaigns[id]
+	if !exists {
+		return ErrCampaignNotFound
+	}
+	campaign.DiscountSpent = campaign.DiscountSpent.Sub(amount)
+	campaign.Redemptions--
+	campaign.Paused = campaign.Exhausted()
+	return nil
+}
+
+// mockRateRepository is a mock implementation of RateRepository interface
+type mockRateRepository struct {
+	tables []RateTable
+}
+
+func newMockRateRepository(tables ...RateTable) *mockRateRepository {
+	m := &mockRateRepository{}
+	for _, table := range tables {
+		_, _ = m.Save(table)
+	}
+	return m
+}
+
+func (m *mockRateRepository) Save(table RateTable) (*RateTable, error) {
+	table.Version = len(m.tables) + 1
+	m.tables = append(m.tables, table)
+	return &table, nil
+}
+
+func (m *mockRateRepository) Active(at time.Time) (*RateTable, error) {
+	for i := len(m.tables) - 1; i >= 0; i-- {
+		if !m.tables[i].ValidFrom.After(at) {
+			table := m.tables[i]
+			return &table, nil
+		}
+	}
+	return nil, ErrRateNotFound
+}
+
+func (m *mockRateRepository) FindByVersion(version int) (*RateTable, error) {
+	if version < 1 || version > len(m.tables) {
+		return nil, ErrRateNotFound
+	}
+	table := m.tables[version-1]
+	return &table, nil
+}
+
+// mockCouponHistory is a mock implementation of CouponHistory interface
+type mockCouponHistory struct {
+	versions []CouponVersion
+	err      error
+}
+
+func newMockCouponHistory() *mockCouponHistory {
+	return &mockCouponHistory{}
+}
+
+func (m *mockCouponHistory) Append(versions ...CouponVersion) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.versions = append(m.versions, versions...)
+	return nil
+}
+
+func (m *mockCouponHistory) Versions(code string) ([]CouponVersion, error) {
+	var versions []CouponVersion
+	for _, version := range m.versions {
+		if version.Code == code {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+func (m *mockCouponHistory) FindVersion(id string) (*CouponVersion, error) {
+	for _, version := range m.versions {
+		if version.ID == id {
+			return &version, nil
+		}
+	}
+	return nil, ErrCouponVersionNotFound
+}
+
+// testActor is the admin changing coupons in the tests
+var testActor = Actor{ID: "admin-1", Role: "admin", RequestID: "req-1"}
+
+// mockAuditLog is a mock implementation of AuditLog interface
+type mockAuditLog struct {
+	entries []AuditEntry
+	err     error
+}
+
+func newMockAuditLog() *mockAuditLog {
+	return &mockAuditLog{}
+}
+
+func (m *mockAuditLog) Append(entries ...AuditEntry) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.entries = append(m.entries, entries...)
 	return nil
 }
 
+func (m *mockAuditLog) Query(query AuditQuery) ([]AuditEntry, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var entries []AuditEntry
+	for i := len(m.entries) - 1; i >= 0 && len(entries) < query.Limit; i-- {
+		if query.Matches(m.entries[i]) {
+			entries = append(entries, m.entries[i])
+		}
+	}
+	return entries, nil
+}
+
 func TestService_ApplyCoupon(t *testing.T) {
 	tests := []struct {
 		name         string
 		basket       Basket
 		code         string
+		customer     Customer
 		setupRepo    func(*mockRepository)
+		setupLedger  func(*mockLedger)
 		expectedErr  string
 		expectBasket *Basket
 	}{
 		{
 			name: "successful coupon application",
 			basket: Basket{
-				Value: 100,
+				Value: eur(100),
 			},
 			code: "TEST10",
 			setupRepo: func(m *mockRepository) {
 				m.coupons["TEST10"] = &Coupon{
 					Code:     "TEST10",
+					Status:   CouponActive,
+					Currency: "EUR",
 					Discount: 10,
 				}
 			},
 			expectBasket: &Basket{
-				Value:                 100,
+				Currency:              "EUR",
+				Value:                 eur(100),
+				ShippingCost:          eur(0),
 				AppliedDiscount:       10,
 				ApplicationSuccessful: true,
 				CouponCode:            "TEST10",
+				OriginalValue:         eur(100),
+				DiscountAmount:        eur(10),
+				FinalValue:            eur(90),
 			},
 		},
 		{
-			name: "empty coupon code",
+			name: "discount rounded to cents",
 			basket: Basket{
-				Value: 100,
+				Value: eur(100.55),
+			},
+			code: "TEST15",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["TEST15"] = &Coupon{
+					Code:     "TEST15",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Discount: 15,
+				}
+			},
+			expectBasket: &Basket{
+				Currency:              "EUR",
+				Value:                 eur(100.55),
+				ShippingCost:          eur(0),
+				AppliedDiscount:       15,
+				ApplicationSuccessful: true,
+				CouponCode:            "TEST15",
+				OriginalValue:         eur(100.55),
+				DiscountAmount:        eur(15.08),
+				FinalValue:            eur(85.47),
 			},
-			code:        "",
-			setupRepo:   func(m *mockRepository) {},
-			expectedErr: "empty coupon code",
 		},
 		{
-			name: "invalid basket value",
+			name: "basket at minimum value",
 			basket: Basket{
-				Value: 0,
+				Value: eur(50),
 			},
-			code: "TEST10",
+			code: "MIN50",
 			setupRepo: func(m *mockRepository) {
-				m.coupons["TEST10"] = &Coupon{
-					Code:     "TEST10",
-					Discount: 10,
+				m.coupons["MIN50"] = &Coupon{
+					Code:           "MIN50",
+					Status:         CouponActive,
+					Currency:       "EUR",
+					Discount:       20,
+					MinBasketValue: eur(50),
 				}
 			},
-			expectedErr: "invalid basket value",
+			expectBasket: &Basket{
+				Currency:              "EUR",
+				Value:                 eur(50),
+				ShippingCost:          eur(0),
+				AppliedDiscount:       20,
+				ApplicationSuccessful: true,
+				CouponCode:            "MIN50",
+				OriginalValue:         eur(50),
+				DiscountAmount:        eur(10),
+				FinalValue:            eur(40),
+			},
 		},
 		{
-			name: "coupon not found",
+			name: "basket below minimum value",
 			basket: Basket{
-				Value: 100,
+				Value: eur(49.99),
 			},
-			code:        "INVALID",
-			setupRepo:   func(m *mockRepository) {},
-			expectedErr: "coupon not found",
+			code: "MIN50",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["MIN50"] = &Coupon{
+					Code:           "MIN50",
+					Status:         CouponActive,
+					Currency:       "EUR",
+					Discount:       20,
+					MinBasketValue: eur(50),
+				}
+			},
+			expectedErr: "basket value below coupon minimum",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := newMockRepository()
-			tt.setupRepo(repo)
-
-			service := New(repo)
-			result, err := service.ApplyCoupon(tt.basket, tt.code)
-
-			if tt.expectedErr != "" {
-				assert.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedErr)
-				assert.Nil(t, result)
-				return
-			}
-
-			assert.NoError(t, err)
-			assert.Equal(t, tt.expectBasket, result)
-		})
-	}
-}
-
-func TestService_CreateCoupon(t *testing.T) {
-	tests := []struct {
-		name           string
-		discount       int
-		code           string
-		minBasketValue float64
-		setupRepo      func(*mockRepository)
-		expectedErr    string
-	}{
 		{
-			name:           "successful coupon creation",
-			discount:       10,
-			code:           "NEW10",
-			minBasketValue: 50,
-			setupRepo:      func(m *mockRepository) {},
+			name: "basket in another currency",
+			basket: Basket{
+				Currency: "USD",
+				Value:    Money{Amount: 10000, Currency: "USD"},
+			},
+			code: "TEST10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Discount: 10, Currency: "EUR"}
+			},
+			expectedErr: "currency mismatch: coupon in EUR, basket in USD",
 		},
 		{
-			name:           "empty code",
-			discount:       10,
-			code:           "",
-			minBasketValue: 50,
-			setupRepo:      func(m *mockRepository) {},
-			expectedErr:    "empty coupon code",
+			name: "basket amount in another currency",
+			basket: Basket{
+				Value:        eur(100),
+				ShippingCost: Money{Amount: 499, Currency: "USD"},
+			},
+			code: "TEST10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10}
+			},
+			expectedErr: "currency mismatch: amount in USD, want EUR",
 		},
 		{
-			name:           "repository error",
-			discount:       10,
-			code:           "ERROR10",
-			minBasketValue: 50,
+			name: "percentage on targeted category",
+			basket: Basket{
+				Items: []LineItem{
+					{SKU: "SHOE-1", Category: "shoes", UnitPrice: eur(50), Quantity: 2},
+					{SKU: "SOCK-1", Category: "socks", UnitPrice: eur(5), Quantity: 4},
+				},
+			},
+			code: "SHOES20",
 			setupRepo: func(m *mockRepository) {
-				m.err = fmt.Errorf("database error")
+				m.coupons["SHOES20"] = &Coupon{
+					Code:              "SHOES20",
+					Status:            CouponActive,
+					Currency:          "EUR",
+					Discount:          20,
+					IncludeCategories: []string{"shoes"},
+				}
+			},
+			expectBasket: &Basket{
+				Currency:     "EUR",
+				Value:        eur(120),
+				ShippingCost: eur(0),
+				Quantity:     6,
+				Items: []LineItem{
+					{SKU: "SHOE-1", Category: "shoes", UnitPrice: eur(50), Quantity: 2, Discount: eur(20)},
+					{SKU: "SOCK-1", Category: "socks", UnitPrice: eur(5), Quantity: 4, Discount: eur(0)},
+				},
+				AppliedDiscount:       20,
+				ApplicationSuccessful: true,
+				CouponCode:            "SHOES20",
+				OriginalValue:         eur(120),
+				DiscountAmount:        eur(20),
+				FinalValue:            eur(100),
 			},
-			expectedErr: "database error",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			repo := newMockRepository()
-			tt.setupRepo(repo)
-
-			service := New(repo)
-			err := service.CreateCoupon(tt.discount, tt.code, tt.minBasketValue)
-
-			if tt.expectedErr != "" {
-				assert.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedErr)
-				return
-			}
-
+		{
+			name: "fixed amount split across lines with excluded sku",
+			basket: Basket{
+				Items: []LineItem{
+					{SKU: "A", Category: "shoes", UnitPrice: eur(20), Quantity: 1},
+					{SKU: "B", Category: "shoes", UnitPrice: eur(10), Quantity: 1},
+					{SKU: "GIFT", Category: "gifts", UnitPrice: eur(25), Quantity: 1},
+				},
+			},
+			code: "FIXED10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["FIXED10"] = &Coupon{
+					Code:        "FIXED10",
+					Status:      CouponActive,
+					Currency:    "EUR",
+					Type:        DiscountFixedAmount,
+					Discount:    10,
+					ExcludeSKUs: []string{"GIFT"},
+				}
+			},
+			expectBasket: &Basket{
+				Currency:     "EUR",
+				Value:        eur(55),
+				ShippingCost: eur(0),
+				Quantity:     3,
+				Items: []LineItem{
+					{SKU: "A", Category: "shoes", UnitPrice: eur(20), Quantity: 1, Discount: eur(6.67)},
+					{SKU: "B", Category: "shoes", UnitPrice: eur(10), Quantity: 1, Discount: eur(3.33)},
+					{SKU: "GIFT", Category: "gifts", UnitPrice: eur(25), Quantity: 1, Discount: eur(0)},
+				},
+				AppliedDiscount:       10,
+				ApplicationSuccessful: true,
+				CouponCode:            "FIXED10",
+				OriginalValue:         eur(55),
+				DiscountAmount:        eur(10),
+				FinalValue:            eur(45),
+			},
+		},
+		{
+			name: "buy two get one frees cheapest eligible units",
+			basket: Basket{
+				Items: []LineItem{
+					{SKU: "TEE-1", Category: "shirts", UnitPrice: eur(15), Quantity: 2},
+					{SKU: "TEE-2", Category: "shirts", UnitPrice: eur(10), Quantity: 1},
+					{SKU: "CAP-1", Category: "hats", UnitPrice: eur(5), Quantity: 3},
+				},
+			},
+			code: "B2G1",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["B2G1"] = &Coupon{
+					Code:              "B2G1",
+					Status:            CouponActive,
+					Currency:          "EUR",
+					Type:              DiscountBuyXGetY,
+					BuyQuantity:       2,
+					GetQuantity:       1,
+					IncludeCategories: []string{"shirts"},
+				}
+			},
+			expectBasket: &Basket{
+				Currency:     "EUR",
+				Value:        eur(55),
+				ShippingCost: eur(0),
+				Quantity:     6,
+				Items: []LineItem{
+					{SKU: "TEE-1", Category: "shirts", UnitPrice: eur(15), Quantity: 2, Discount: eur(0)},
+					{SKU: "TEE-2", Category: "shirts", UnitPrice: eur(10), Quantity: 1, Discount: eur(10)},
+					{SKU: "CAP-1", Category: "hats", UnitPrice: eur(5), Quantity: 3, Discount: eur(0)},
+				},
+				ApplicationSuccessful: true,
+				CouponCode:            "B2G1",
+				OriginalValue:         eur(55),
+				DiscountAmount:        eur(10),
+				FinalValue:            eur(45),
+			},
+		},
+		{
+			name: "no eligible items",
+			basket: Basket{
+				Items: []LineItem{
+					{SKU: "SOCK-1", Category: "socks", UnitPrice: eur(5), Quantity: 4},
+				},
+			},
+			code: "SHOES20",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["SHOES20"] = &Coupon{
+					Code:              "SHOES20",
+					Status:            CouponActive,
+					Currency:          "EUR",
+					Discount:          20,
+					IncludeCategories: []string{"shoes"},
+				}
+			},
+			expectedErr: "no eligible items in basket",
+		},
+		{
+			name: "targeted coupon without line items",
+			basket: Basket{
+				Value: eur(100),
+			},
+			code: "SHOES20",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["SHOES20"] = &Coupon{
+					Code:              "SHOES20",
+					Status:            CouponActive,
+					Currency:          "EUR",
+					Discount:          20,
+					IncludeCategories: []string{"shoes"},
+				}
+			},
+			expectedErr: "no eligible items in basket",
+		},
+		{
+			name: "invalid line item quantity",
+			basket: Basket{
+				Items: []LineItem{
+					{SKU: "SHOE-1", Category: "shoes", UnitPrice: eur(50), Quantity: 0},
+				},
+			},
+			code: "TEST10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10}
+			},
+			expectedErr: "invalid basket item",
+		},
+		{
+			name: "global redemption limit reached",
+			basket: Basket{
+				Value: eur(100),
+			},
+			code:     "LIMITED",
+			customer: Customer{ID: "123"},
+			setupRepo: func(m *mockRepository) {
+				m.coupons["LIMITED"] = &Coupon{
+					Code:           "LIMITED",
+					Status:         CouponActive,
+					Currency:       "EUR",
+					Discount:       10,
+					MaxRedemptions: 1,
+				}
+			},
+			setupLedger: func(m *mockLedger) {
+				m.err = ErrRedemptionLimitReached
+			},
+			expectedErr: "coupon redemption limit reached",
+		},
+		{
+			name: "per customer limit without customer",
+			basket: Basket{
+				Value: eur(100),
+			},
+			code: "ONCE",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["ONCE"] = &Coupon{
+					Code:           "ONCE",
+					Status:         CouponActive,
+					Currency:       "EUR",
+					Discount:       10,
+					MaxPerCustomer: 1,
+				}
+			},
+			expectedErr: "customer id required",
+		},
+		{
+			name: "coupon not yet active",
+			basket: Basket{
+				Value: eur(100),
+			},
+			code: "SUMMER2024",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["SUMMER2024"] = &Coupon{
+					Code:     "SUMMER2024",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Discount: 10,
+					StartsAt: time.Now().Add(time.Hour),
+				}
+			},
+			expectedErr: "coupon not yet active",
+		},
+		{
+			name: "coupon expired",
+			basket: Basket{
+				Value: eur(100),
+			},
+			code: "SUMMER2024",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["SUMMER2024"] = &Coupon{
+					Code:      "SUMMER2024",
+					Status:    CouponActive,
+					Currency:  "EUR",
+					Discount:  10,
+					StartsAt:  time.Now().Add(-48 * time.Hour),
+					ExpiresAt: time.Now().Add(-time.Hour),
+				}
+			},
+			expectedErr: "coupon expired",
+		},
+		{
+			name: "coupon within validity window",
+			basket: Basket{
+				Value: eur(100),
+			},
+			code: "SUMMER2024",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["SUMMER2024"] = &Coupon{
+					Code:      "SUMMER2024",
+					Status:    CouponActive,
+					Currency:  "EUR",
+					Discount:  10,
+					StartsAt:  time.Now().Add(-time.Hour),
+					ExpiresAt: time.Now().Add(time.Hour),
+				}
+			},
+			expectBasket: &Basket{
+				Currency:              "EUR",
+				Value:                 eur(100),
+				ShippingCost:          eur(0),
+				AppliedDiscount:       10,
+				ApplicationSuccessful: true,
+				CouponCode:            "SUMMER2024",
+				OriginalValue:         eur(100),
+				DiscountAmount:        eur(10),
+				FinalValue:            eur(90),
+			},
+		},
+		{
+			name: "fixed amount discount",
+			basket: Basket{
+				Value: eur(30),
+			},
+			code: "FIXED10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["FIXED10"] = &Coupon{
+					Code:     "FIXED10",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Type:     DiscountFixedAmount,
+					Discount: 10,
+				}
+			},
+			expectBasket: &Basket{
+				Currency:              "EUR",
+				Value:                 eur(30),
+				ShippingCost:          eur(0),
+				AppliedDiscount:       10,
+				ApplicationSuccessful: true,
+				CouponCode:            "FIXED10",
+				OriginalValue:         eur(30),
+				DiscountAmount:        eur(10),
+				FinalValue:            eur(20),
+			},
+		},
+		{
+			name: "fixed amount capped at basket value",
+			basket: Basket{
+				Value:        eur(8),
+				ShippingCost: eur(5),
+			},
+			code: "FIXED10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["FIXED10"] = &Coupon{
+					Code:     "FIXED10",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Type:     DiscountFixedAmount,
+					Discount: 10,
+				}
+			},
+			expectBasket: &Basket{
+				Currency:              "EUR",
+				Value:                 eur(8),
+				ShippingCost:          eur(5),
+				AppliedDiscount:       10,
+				ApplicationSuccessful: true,
+				CouponCode:            "FIXED10",
+				OriginalValue:         eur(8),
+				DiscountAmount:        eur(8),
+				FinalValue:            eur(5),
+			},
+		},
+		{
+			name: "free shipping",
+			basket: Basket{
+				Value:        eur(40),
+				ShippingCost: eur(4.99),
+			},
+			code: "SHIPFREE",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["SHIPFREE"] = &Coupon{
+					Code:     "SHIPFREE",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Type:     DiscountFreeShipping,
+				}
+			},
+			expectBasket: &Basket{
+				Currency:              "EUR",
+				Value:                 eur(40),
+				ShippingCost:          eur(4.99),
+				ApplicationSuccessful: true,
+				CouponCode:            "SHIPFREE",
+				OriginalValue:         eur(40),
+				DiscountAmount:        eur(4.99),
+				FinalValue:            eur(40),
+			},
+		},
+		{
+			name: "buy two get one",
+			basket: Basket{
+				Value:    eur(70),
+				Quantity: 7,
+			},
+			code: "B2G1",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["B2G1"] = &Coupon{
+					Code:        "B2G1",
+					Status:      CouponActive,
+					Currency:    "EUR",
+					Type:        DiscountBuyXGetY,
+					BuyQuantity: 2,
+					GetQuantity: 1,
+				}
+			},
+			expectBasket: &Basket{
+				Currency:              "EUR",
+				Value:                 eur(70),
+				ShippingCost:          eur(0),
+				Quantity:              7,
+				ApplicationSuccessful: true,
+				CouponCode:            "B2G1",
+				OriginalValue:         eur(70),
+				DiscountAmount:        eur(20),
+				FinalValue:            eur(50),
+			},
+		},
+		{
+			name: "buy two get one with too few units",
+			basket: Basket{
+				Value:    eur(20),
+				Quantity: 2,
+			},
+			code: "B2G1",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["B2G1"] = &Coupon{
+					Code:        "B2G1",
+					Status:      CouponActive,
+					Currency:    "EUR",
+					Type:        DiscountBuyXGetY,
+					BuyQuantity: 2,
+					GetQuantity: 1,
+				}
+			},
+			expectedErr: "no eligible items in basket: coupon takes nothing off the basket",
+		},
+		{
+			name: "free shipping without shipping cost",
+			basket: Basket{
+				Value: eur(40),
+			},
+			code: "SHIPFREE",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["SHIPFREE"] = &Coupon{
+					Code:     "SHIPFREE",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Type:     DiscountFreeShipping,
+				}
+			},
+			expectedErr: "no eligible items in basket: coupon takes nothing off the basket",
+		},
+		{
+			name: "empty coupon code",
+			basket: Basket{
+				Value: eur(100),
+			},
+			code:        "",
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "empty coupon code",
+		},
+		{
+			name: "invalid basket value",
+			basket: Basket{
+				Value: eur(0),
+			},
+			code: "TEST10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["TEST10"] = &Coupon{
+					Code:     "TEST10",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Discount: 10,
+				}
+			},
+			expectedErr: "invalid basket value",
+		},
+		{
+			name: "negative shipping cost",
+			basket: Basket{
+				Value:        eur(100),
+				ShippingCost: eur(-50),
+			},
+			code: "FREESHIP",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["FREESHIP"] = &Coupon{
+					Code:     "FREESHIP",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Type:     DiscountFreeShipping,
+				}
+			},
+			expectedErr: "shipping cost and quantity must not be negative",
+		},
+		{
+			name: "negative basket value",
+			basket: Basket{
+				Value: eur(-100),
+			},
+			code: "TEST10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["TEST10"] = &Coupon{
+					Code:     "TEST10",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Discount: 10,
+				}
+			},
+			expectedErr: "must not be negative",
+		},
+		{
+			name: "basket value out of range",
+			basket: Basket{
+				Items: []LineItem{{SKU: "A", UnitPrice: eur(1e12), Quantity: 1 << 40}},
+			},
+			code: "TEST10",
+			setupRepo: func(m *mockRepository) {
+				m.coupons["TEST10"] = &Coupon{
+					Code:     "TEST10",
+					Status:   CouponActive,
+					Currency: "EUR",
+					Discount: 10,
+				}
+			},
+			expectedErr: "basket value must be at most",
+		},
+		{
+			name: "coupon not found",
+			basket: Basket{
+				Value: eur(100),
+			},
+			code:        "INVALID",
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "coupon not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			tt.setupRepo(repo)
+			ledger := newMockLedger()
+			if tt.setupLedger != nil {
+				tt.setupLedger(ledger)
+			}
+
+			service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			result, err := service.ApplyCoupon(tt.basket, tt.code, tt.customer)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Nil(t, result)
+				assert.Empty(t, ledger.redemptions)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectBasket, result)
+			assert.Len(t, ledger.redemptions, 1)
+			assert.Equal(t, tt.code, ledger.redemptions[0].Code)
+			assert.Equal(t, tt.customer.ID, ledger.redemptions[0].CustomerID)
+		})
+	}
+}
+
+func TestService_CreateCoupon(t *testing.T) {
+	tests := []struct {
+		name         string
+		coupon       Coupon
+		setupRepo    func(*mockRepository)
+		expectedErr  string
+		expectedType DiscountType
+	}{
+		{
+			name:         "successful coupon creation",
+			coupon:       Coupon{Code: "NEW10", Discount: 10, MinBasketValue: eur(50)},
+			setupRepo:    func(m *mockRepository) {},
+			expectedType: DiscountPercentage,
+		},
+		{
+			name:        "empty code",
+			coupon:      Coupon{Code: "", Discount: 10, MinBasketValue: eur(50)},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "empty coupon code",
+		},
+		{
+			name:   "repository error",
+			coupon: Coupon{Code: "ERROR10", Discount: 10, MinBasketValue: eur(50)},
+			setupRepo: func(m *mockRepository) {
+				m.err = fmt.Errorf("database error")
+			},
+			expectedErr: "database error",
+		},
+		{
+			name:         "free shipping without discount value",
+			coupon:       Coupon{Code: "SHIP", Type: DiscountFreeShipping, MinBasketValue: eur(50)},
+			setupRepo:    func(m *mockRepository) {},
+			expectedType: DiscountFreeShipping,
+		},
+		{
+			name:        "percentage above 100",
+			coupon:      Coupon{Code: "HUGE", Type: DiscountPercentage, Discount: 120},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "invalid coupon",
+		},
+		{
+			name:        "buy x get y without quantities",
+			coupon:      Coupon{Code: "BXGY", Type: DiscountBuyXGetY},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "invalid coupon",
+		},
+		{
+			name:        "negative redemption limit",
+			coupon:      Coupon{Code: "NEG", Discount: 10, MaxRedemptions: -1},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "redemption limits must not be negative",
+		},
+		{
+			name: "expiry before start",
+			coupon: Coupon{
+				Code:      "BACKWARDS",
+				Discount:  10,
+				StartsAt:  time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+				ExpiresAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "expiry must be after start",
+		},
+		{
+			name:        "unknown discount type",
+			coupon:      Coupon{Code: "ODD", Type: "mystery", Discount: 10},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "unknown discount type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			tt.setupRepo(repo)
+
+			service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			err := service.CreateCoupon(tt.coupon, testActor)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			// Verify coupon was saved
+			saved, err := repo.FindByCode(tt.coupon.Code)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, saved.ID)
+			assert.Equal(t, tt.expectedType, saved.Type)
+			assert.Equal(t, tt.coupon.Discount, saved.Discount)
+			assert.Equal(t, tt.coupon.MinBasketValue, saved.MinBasketValue)
+		})
+	}
+}
+
+func TestService_GetCoupons(t *testing.T) {
+	tests := []struct {
+		name        string
+		codes       []string
+		setupRepo   func(*mockRepository)
+		expectedErr string
+		expectCount int
+	}{
+		{
+			name:  "successful multiple coupons retrieval",
+			codes: []string{"CODE1", "CODE2"},
+			setupRepo: func(m *mockRepository) {
+				m.coupons["CODE1"] = &Coupon{Code: "CODE1", Status: CouponActive, Currency: "EUR", Discount: 10}
+				m.coupons["CODE2"] = &Coupon{Code: "CODE2", Status: CouponActive, Currency: "EUR", Discount: 20}
+			},
+			expectCount: 2,
+		},
+		{
+			name:  "one invalid code",
+			codes: []string{"CODE1", "INVALID"},
+			setupRepo: func(m *mockRepository) {
+				m.coupons["CODE1"] = &Coupon{Code: "CODE1", Status: CouponActive, Currency: "EUR", Discount: 10}
+			},
+			expectedErr: "error finding coupon INVALID",
+		},
+		{
+			name:        "empty codes list",
+			codes:       []string{},
+			setupRepo:   func(m *mockRepository) {},
+			expectCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			tt.setupRepo(repo)
+
+			service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			coupons, err := service.GetCoupons(tt.codes)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Nil(t, coupons)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, coupons, tt.expectCount)
+			for i, code := range tt.codes {
+				assert.Equal(t, code, coupons[i].Code)
+			}
+		})
+	}
+}
+
+func TestService_ReserveCoupon(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10, MaxRedemptions: 1}
+	ledger := newMockLedger()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	service.now = func() time.Time { return now }
+
+	result, err := service.ReserveCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "123"})
+	assert.NoError(t, err)
+	assert.Equal(t, eur(10), result.DiscountAmount)
+	assert.NotEmpty(t, result.ReservationID)
+	assert.Equal(t, now.Add(DefaultReservationTTL), *result.ReservedUntil)
+	assert.Empty(t, ledger.redemptions)
+
+	reservation := ledger.reservations[result.ReservationID]
+	assert.Equal(t, "TEST10", reservation.Code)
+	assert.Equal(t, "123", reservation.CustomerID)
+
+	assert.ErrorIs(t, service.CommitReservation(result.ReservationID, Customer{ID: "456"}), ErrReservationNotFound)
+	assert.Empty(t, ledger.redemptions)
+
+	assert.NoError(t, service.CommitReservation(result.ReservationID, Customer{ID: "123"}))
+	assert.Len(t, ledger.redemptions, 1)
+	assert.ErrorIs(t, service.CommitReservation(result.ReservationID, Customer{ID: "123"}), ErrReservationNotFound)
+}
+
+func TestService_ReserveCoupon_Invalid(t *testing.T) {
+	repo := newMockRepository()
+	ledger := newMockLedger()
+
+	service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	result, err := service.ReserveCoupon(Basket{Value: eur(100)}, "INVALID", Customer{ID: "123"})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Empty(t, ledger.reservations)
+}
+
+func TestService_ReleaseReservation(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10}
+	ledger := newMockLedger()
+
+	service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	result, err := service.ReserveCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "123"})
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, service.ReleaseReservation(result.ReservationID, Customer{ID: "456"}), ErrReservationNotFound)
+	assert.Contains(t, ledger.reservations, result.ReservationID)
+
+	assert.NoError(t, service.ReleaseReservation(result.ReservationID, Customer{ID: "123"}))
+	assert.Empty(t, ledger.reservations)
+	assert.Empty(t, ledger.redemptions)
+	assert.ErrorIs(t, service.ReleaseReservation(result.ReservationID, Customer{ID: "123"}), ErrReservationNotFound)
+}
+
+func TestService_ApplyCoupons(t *testing.T) {
+	coupons := map[string]*Coupon{
+		"TEN":      {Code: "TEN", Status: CouponActive, Currency: "EUR", Discount: 10, Stackable: true, Priority: 2},
+		"FIVE":     {Code: "FIVE", Status: CouponActive, Currency: "EUR", Type: DiscountFixedAmount, Discount: 5, Stackable: true, Priority: 1},
+		"SUMMER":   {Code: "SUMMER", Status: CouponActive, Currency: "EUR", Discount: 15, Stackable: true, ExclusivityGroup: "seasonal"},
+		"WINTER":   {Code: "WINTER", Status: CouponActive, Currency: "EUR", Discount: 12, Stackable: true, ExclusivityGroup: "seasonal"},
+		"SOLO30":   {Code: "SOLO30", Status: CouponActive, Currency: "EUR", Discount: 30},
+		"SOLO5":    {Code: "SOLO5", Status: CouponActive, Currency: "EUR", Discount: 5},
+		"SHIPFREE": {Code: "SHIPFREE", Status: CouponActive, Currency: "EUR", Type: DiscountFreeShipping, Stackable: true},
+	}
+
+	tests := []struct {
+		name           string
+		basket         Basket
+		codes          []string
+		codeErr        map[string]error
+		expectedErr    string
+		expectApplied  []AppliedCoupon
+		expectDropped  []DroppedCoupon
+		expectDiscount Money
+	}{
+		{
+			name:   "stackable coupons combine in priority order",
+			basket: Basket{Value: eur(100), ShippingCost: eur(5)},
+			codes:  []string{"FIVE", "TEN", "SHIPFREE"},
+			expectApplied: []AppliedCoupon{
+				{Code: "TEN", DiscountAmount: eur(10)},
+				{Code: "FIVE", DiscountAmount: eur(5)},
+				{Code: "SHIPFREE", DiscountAmount: eur(5)},
+			},
+			expectDiscount: eur(20),
+		},
+		{
+			name:          "non stackable coupon wins when larger",
+			basket:        Basket{Value: eur(100)},
+			codes:         []string{"TEN", "FIVE", "SOLO30"},
+			expectApplied: []AppliedCoupon{{Code: "SOLO30", DiscountAmount: eur(30)}},
+			expectDropped: []DroppedCoupon{
+				{Code: "TEN", Detail: "coupon not combinable: coupon SOLO30 cannot be combined with other coupons", Err: ErrCouponNotCombinable},
+				{Code: "FIVE", Detail: "coupon not combinable: coupon SOLO30 cannot be combined with other coupons", Err: ErrCouponNotCombinable},
+			},
+			expectDiscount: eur(30),
+		},
+		{
+			name:   "stackable coupons win over smaller non stackable coupon",
+			basket: Basket{Value: eur(100)},
+			codes:  []string{"SOLO5", "TEN"},
+			expectApplied: []AppliedCoupon{
+				{Code: "TEN", DiscountAmount: eur(10)},
+			},
+			expectDropped: []DroppedCoupon{
+				{Code: "SOLO5", Detail: "coupon not combinable: coupon cannot be combined with other coupons", Err: ErrCouponNotCombinable},
+			},
+			expectDiscount: eur(10),
+		},
+		{
+			name:   "one coupon per exclusivity group",
+			basket: Basket{Value: eur(100)},
+			codes:  []string{"WINTER", "SUMMER", "TEN"},
+			expectApplied: []AppliedCoupon{
+				{Code: "TEN", DiscountAmount: eur(10)},
+				{Code: "SUMMER", DiscountAmount: eur(13.50)},
+			},
+			expectDropped: []DroppedCoupon{
+				{Code: "WINTER", Detail: "exclusivity group already used: coupon SUMMER is exclusive in group seasonal", Err: ErrExclusivityConflict},
+			},
+			expectDiscount: eur(23.50),
+		},
+		{
+			name:          "invalid and duplicate codes",
+			basket:        Basket{Value: eur(100)},
+			codes:         []string{"TEN", "UNKNOWN", "TEN"},
+			expectApplied: []AppliedCoupon{{Code: "TEN", DiscountAmount: eur(10)}},
+			expectDropped: []DroppedCoupon{
+				{Code: "UNKNOWN", Detail: "coupon not found", Err: ErrCouponNotFound},
+			},
+			expectDiscount: eur(10),
+		},
+		{
+			name:          "limit reached falls back to next best combination",
+			basket:        Basket{Value: eur(100)},
+			codes:         []string{"SOLO30", "TEN"},
+			codeErr:       map[string]error{"SOLO30": ErrRedemptionLimitReached},
+			expectApplied: []AppliedCoupon{{Code: "TEN", DiscountAmount: eur(10)}},
+			expectDropped: []DroppedCoupon{
+				{Code: "SOLO30", Detail: "coupon redemption limit reached", Err: ErrRedemptionLimitReached},
+			},
+			expectDiscount: eur(10),
+		},
+		{
+			name:           "all codes dropped",
+			basket:         Basket{Value: eur(100)},
+			codes:          []string{"UNKNOWN"},
+			expectApplied:  []AppliedCoupon{},
+			expectDropped:  []DroppedCoupon{{Code: "UNKNOWN", Detail: "coupon not found", Err: ErrCouponNotFound}},
+			expectDiscount: eur(0),
+		},
+		{
+			name:        "too many codes",
+			basket:      Basket{Value: eur(100)},
+			codes:       []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K"},
+			expectedErr: "too many coupon codes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			for code, coupon := range coupons {
+				repo.coupons[code] = coupon
+			}
+			ledger := newMockLedger()
+			ledger.codeErr = tt.codeErr
+
+			service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			result, err := service.ApplyCoupons(tt.basket, tt.codes, Customer{ID: "123"})
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectApplied, result.Applied)
+			assertDropped(t, tt.expectDropped, result.Dropped)
+			assert.Equal(t, tt.expectDiscount, result.Basket.DiscountAmount)
+			assert.Equal(t, tt.basket.Value.Add(tt.basket.ShippingCost).Sub(tt.expectDiscount), result.Basket.FinalValue)
+			assert.Len(t, ledger.redemptions, len(tt.expectApplied))
+			assert.Empty(t, ledger.reservations)
+		})
+	}
+}
+
+func TestService_ApplyCoupons_StoreError(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEN"] = &Coupon{Code: "TEN", Status: CouponActive, Currency: "EUR", Discount: 10, Stackable: true}
+	repo.coupons["LOST"] = &Coupon{Code: "LOST", Status: CouponActive, Currency: "EUR", Discount: 5, Stackable: true, CampaignID: "missing"}
+	ledger := newMockLedger()
+
+	service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	result, err := service.ApplyCoupons(Basket{Value: eur(100)}, []string{"TEN", "LOST"}, Customer{ID: "123"})
+	assert.ErrorIs(t, err, ErrCampaignNotFound)
+	assert.Nil(t, result)
+	assert.Empty(t, ledger.redemptions)
+
+	recommendation, err := service.RecommendCoupons(Basket{Value: eur(100)}, []string{"TEN", "LOST"}, Customer{ID: "123"})
+	assert.ErrorIs(t, err, ErrCampaignNotFound)
+	assert.Nil(t, recommendation)
+}
+
+func TestService_ApplyCoupons_LineItems(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["SHOES80"] = &Coupon{Code: "SHOES80", Status: CouponActive, Currency: "EUR", Type: DiscountFixedAmount, Discount: 80, IncludeSKUs: []string{"A"}, Stackable: true, Priority: 2}
+	repo.coupons["HALF"] = &Coupon{Code: "HALF", Status: CouponActive, Currency: "EUR", Discount: 50, Stackable: true, Priority: 1}
+
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	basket := Basket{Items: []LineItem{
+		{SKU: "A", UnitPrice: eur(100), Quantity: 1},
+		{SKU: "B", UnitPrice: eur(20), Quantity: 1},
+	}}
+	result, err := service.ApplyCoupons(basket, []string{"HALF", "SHOES80"}, Customer{ID: "123"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []AppliedCoupon{
+		{Code: "SHOES80", DiscountAmount: eur(80)},
+		{Code: "HALF", DiscountAmount: eur(20)},
+	}, result.Applied)
+	assert.Equal(t, eur(90), result.Basket.Items[0].Discount)
+	assert.Equal(t, eur(10), result.Basket.Items[1].Discount)
+	assert.Equal(t, eur(100), result.Basket.DiscountAmount)
+	assert.Equal(t, eur(20), result.Basket.FinalValue)
+}
+
+func TestService_GenerateCoupons(t *testing.T) {
+	tests := []struct {
+		name        string
+		template    Coupon
+		spec        CodeSpec
+		setupRepo   func(*mockRepository)
+		expectedErr string
+		expectBatch int
+	}{
+		{
+			name:        "codes with prefix",
+			template:    Coupon{Discount: 10, MinBasketValue: eur(20)},
+			spec:        CodeSpec{Count: 50, Prefix: "SUMMER-", Length: 6},
+			setupRepo:   func(m *mockRepository) {},
+			expectBatch: 1,
+		},
+		{
+			name:        "written in batches",
+			template:    Coupon{Type: DiscountFixedAmount, Discount: 5},
+			spec:        CodeSpec{Count: GenerationBatchSize + 1},
+			setupRepo:   func(m *mockRepository) {},
+			expectBatch: 2,
+		},
+		{
+			name:        "avoid ambiguous characters",
+			template:    Coupon{Discount: 10},
+			spec:        CodeSpec{Count: 20, Alphabet: "O0I1LAB", Length: 8, AvoidAmbiguous: true},
+			setupRepo:   func(m *mockRepository) {},
+			expectBatch: 1,
+		},
+		{
+			name:        "avoid lowercase ambiguous characters",
+			template:    Coupon{Discount: 10},
+			spec:        CodeSpec{Count: 20, Alphabet: "olilab", Length: 8, AvoidAmbiguous: true},
+			setupRepo:   func(m *mockRepository) {},
+			expectBatch: 1,
+		},
+		{
+			name:        "keyspace too small",
+			template:    Coupon{Discount: 10},
+			spec:        CodeSpec{Count: 100, Alphabet: "AB", Length: 4},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "cannot produce 100 unique codes",
+		},
+		{
+			name:        "alphabet reduced to one character",
+			template:    Coupon{Discount: 10},
+			spec:        CodeSpec{Count: 1, Alphabet: "0OA", AvoidAmbiguous: true},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "alphabet needs at least two distinct characters",
+		},
+		{
+			name:        "count above maximum",
+			template:    Coupon{Discount: 10},
+			spec:        CodeSpec{Count: MaxGeneratedCodes + 1},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "count must be between 1 and",
+		},
+		{
+			name:        "invalid template",
+			template:    Coupon{Type: DiscountPercentage, Discount: 0},
+			spec:        CodeSpec{Count: 10},
+			setupRepo:   func(m *mockRepository) {},
+			expectedErr: "invalid coupon",
+		},
+		{
+			name:     "repository error",
+			template: Coupon{Discount: 10},
+			spec:     CodeSpec{Count: 10},
+			setupRepo: func(m *mockRepository) {
+				m.err = fmt.Errorf("database error")
+			},
+			expectedErr: "database error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			tt.setupRepo(repo)
+
+			service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			codes, err := service.GenerateCoupons(tt.template, tt.spec, testActor)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Nil(t, codes)
+				return
+			}
+
 			assert.NoError(t, err)
-			// Verify coupon was saved
-			saved, err := repo.FindByCode(tt.code)
+			assert.Len(t, codes, tt.spec.Count)
+			assert.Len(t, repo.coupons, tt.spec.Count)
+			assert.Equal(t, tt.expectBatch, repo.batches)
+
+			length := tt.spec.Length
+			if length == 0 {
+				length = 8
+			}
+			for _, code := range codes {
+				assert.True(t, strings.HasPrefix(code, tt.spec.Prefix))
+				assert.Len(t, code, len(tt.spec.Prefix)+length)
+				if tt.spec.AvoidAmbiguous {
+					assert.NotContains(t, code, "0")
+					assert.NotContains(t, code, "O")
+					assert.NotContains(t, code, "o")
+					assert.NotContains(t, code, "l")
+				}
+
+				saved := repo.coupons[code]
+				assert.Equal(t, 1, saved.MaxRedemptions)
+				assert.Equal(t, tt.template.Discount, saved.Discount)
+				assert.NotEmpty(t, saved.ID)
+			}
+		})
+	}
+}
+
+func TestService_CreateCampaign(t *testing.T) {
+	tests := []struct {
+		name        string
+		campaign    Campaign
+		expectedErr string
+	}{
+		{
+			name:     "valid campaign",
+			campaign: Campaign{Name: "Summer", DiscountBudget: eur(500), RedemptionBudget: 100},
+		},
+		{
+			name:     "unlimited budgets",
+			campaign: Campaign{Name: "Evergreen"},
+		},
+		{
+			name:        "empty name",
+			campaign:    Campaign{DiscountBudget: eur(500)},
+			expectedErr: "empty campaign name",
+		},
+		{
+			name:        "negative budget",
+			campaign:    Campaign{Name: "Summer", DiscountBudget: eur(-1)},
+			expectedErr: "campaign budgets must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaigns := newMockCampaignRepository()
+			service := New(newMockRepository(), newMockLedger(), campaigns, newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+			result, err := service.CreateCampaign(tt.campaign)
+
+			if tt.expectedErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				assert.Empty(t, campaigns.campaigns)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, result.ID)
+			assert.False(t, result.Paused)
+
+			found, err := service.GetCampaign(result.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, result, found)
+		})
+	}
+}
+
+func TestService_CreateCoupon_CampaignCurrency(t *testing.T) {
+	campaigns := newMockCampaignRepository()
+	campaigns.Save(Campaign{ID: "c1", Name: "Summer", Currency: "EUR"})
+	service := New(newMockRepository(), newMockLedger(), campaigns, newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	err := service.CreateCoupon(Coupon{Code: "TEST10", Discount: 10, Currency: "USD", CampaignID: "c1"}, testActor)
+
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestService_CreateCoupon_UnknownCampaign(t *testing.T) {
+	service := New(newMockRepository(), newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	err := service.CreateCoupon(Coupon{Code: "TEST10", Discount: 10, CampaignID: "missing"}, testActor)
+
+	assert.ErrorIs(t, err, ErrCampaignNotFound)
+}
+
+func TestService_ApplyCoupon_Campaign(t *testing.T) {
+	tests := []struct {
+		name         string
+		campaign     Campaign
+		expectedErr  error
+		expectSpent  Money
+		expectPaused bool
+	}{
+		{
+			name:        "discount charged to campaign",
+			campaign:    Campaign{ID: "c1", Name: "Summer", Currency: "EUR", DiscountBudget: eur(100)},
+			expectSpent: eur(10),
+		},
+		{
+			name:         "last redemption pauses campaign",
+			campaign:     Campaign{ID: "c1", Name: "Summer", Currency: "EUR", RedemptionBudget: 3, Redemptions: 2},
+			expectSpent:  eur(10),
+			expectPaused: true,
+		},
+		{
+			name:         "discount above remaining budget",
+			campaign:     Campaign{ID: "c1", Name: "Summer", Currency: "EUR", DiscountBudget: eur(100), DiscountSpent: eur(95)},
+			expectedErr:  ErrCampaignBudgetExhausted,
+			expectSpent:  eur(95),
+			expectPaused: false,
+		},
+		{
+			name:         "paused campaign",
+			campaign:     Campaign{ID: "c1", Name: "Summer", Currency: "EUR", DiscountBudget: eur(100), DiscountSpent: eur(100), Paused: true},
+			expectedErr:  ErrCampaignPaused,
+			expectSpent:  eur(100),
+			expectPaused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10, CampaignID: "c1"}
+			ledger := newMockLedger()
+			campaigns := newMockCampaignRepository()
+			campaigns.Save(tt.campaign)
+
+			service := New(repo, ledger, campaigns, newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			result, err := service.ApplyCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "123"})
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+				assert.Empty(t, ledger.redemptions)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, ledger.redemptions, 1)
+				assert.Equal(t, "c1", ledger.redemptions[0].CampaignID)
+			}
+			assert.Equal(t, tt.expectSpent, campaigns.campaigns["c1"].DiscountSpent)
+			assert.Equal(t, tt.expectPaused, campaigns.campaigns["c1"].Paused)
+		})
+	}
+}
+
+func TestService_ApplyCoupon_CampaignRefundedOnLedgerError(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10, CampaignID: "c1"}
+	ledger := newMockLedger()
+	ledger.err = ErrRedemptionLimitReached
+	campaigns := newMockCampaignRepository()
+	campaigns.Save(Campaign{ID: "c1", Name: "Summer", Currency: "EUR", DiscountBudget: eur(100)})
+
+	service := New(repo, ledger, campaigns, newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	_, err := service.ApplyCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "123"})
+
+	assert.ErrorIs(t, err, ErrRedemptionLimitReached)
+	assert.Equal(t, eur(0), campaigns.campaigns["c1"].DiscountSpent)
+	assert.Equal(t, 0, campaigns.campaigns["c1"].Redemptions)
+}
+
+func TestService_ApplyCoupon_CampaignExhaustedPausesCoupons(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Discount: 10, CampaignID: "c1", Status: CouponActive, Currency: "EUR"}
+	repo.coupons["SISTER"] = &Coupon{Code: "SISTER", Status: CouponActive, Currency: "EUR", Discount: 5, CampaignID: "c1"}
+	repo.coupons["DRAFT"] = &Coupon{Code: "DRAFT", Discount: 5, CampaignID: "c1", Status: CouponDraft, Currency: "EUR"}
+	repo.coupons["OTHER"] = &Coupon{Code: "OTHER", Discount: 5, Status: CouponActive, Currency: "EUR"}
+	campaigns := newMockCampaignRepository()
+	campaigns.Save(Campaign{ID: "c1", Name: "Summer", Currency: "EUR", RedemptionBudget: 1})
+	audit := newMockAuditLog()
+
+	service := New(repo, newMockLedger(), campaigns, newMockRateRepository(), audit, newMockCouponHistory())
+	_, err := service.ApplyCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "123"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, CouponPaused, repo.coupons["TEST10"].Status)
+	assert.Equal(t, CouponPaused, repo.coupons["SISTER"].Status)
+	assert.Equal(t, CouponDraft, repo.coupons["DRAFT"].Status)
+	assert.Equal(t, CouponActive, repo.coupons["OTHER"].Status)
+
+	var paused []string
+	for _, entry := range audit.entries {
+		assert.Equal(t, AuditStatus, entry.Action)
+		assert.Equal(t, "campaign-budget", entry.ActorID)
+		paused = append(paused, entry.Code)
+	}
+	assert.ElementsMatch(t, []string{"TEST10", "SISTER"}, paused)
+}
+
+func TestService_CommitReservation_Campaign(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10, CampaignID: "c1"}
+	ledger := newMockLedger()
+	campaigns := newMockCampaignRepository()
+	campaigns.Save(Campaign{ID: "c1", Name: "Summer", Currency: "EUR", RedemptionBudget: 1})
+
+	service := New(repo, ledger, campaigns, newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	first, err := service.ReserveCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "123"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, campaigns.campaigns["c1"].Redemptions)
+	assert.True(t, campaigns.campaigns["c1"].Paused)
+	assert.Equal(t, CouponPaused, repo.coupons["TEST10"].Status)
+
+	_, err = service.ReserveCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "456"})
+	assert.ErrorIs(t, err, ErrCouponNotActive)
+	assert.Len(t, ledger.reservations, 1)
+
+	assert.NoError(t, service.CommitReservation(first.ReservationID, Customer{ID: "123"}))
+	assert.Equal(t, 1, campaigns.campaigns["c1"].Redemptions)
+	assert.Len(t, ledger.redemptions, 1)
+}
+
+func TestService_ReserveCoupon_CampaignRefunded(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10, CampaignID: "c1"}
+	ledger := newMockLedger()
+	campaigns := newMockCampaignRepository()
+	campaigns.Save(Campaign{ID: "c1", Name: "Summer", Currency: "EUR", DiscountBudget: eur(100)})
+
+	service := New(repo, ledger, campaigns, newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	service.now = func() time.Time { return now }
+	reserve := func(customerID string) string {
+		result, err := service.ReserveCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: customerID})
+		assert.NoError(t, err)
+		return result.ReservationID
+	}
+
+	released := reserve("123")
+	assert.Equal(t, eur(10), campaigns.campaigns["c1"].DiscountSpent)
+	assert.NoError(t, service.ReleaseReservation(released, Customer{ID: "123"}))
+	assert.Equal(t, eur(0), campaigns.campaigns["c1"].DiscountSpent)
+
+	expired := reserve("123")
+	late := reserve("456")
+	swept := reserve("789")
+	assert.Equal(t, eur(30), campaigns.campaigns["c1"].DiscountSpent)
+
+	now = now.Add(DefaultReservationTTL)
+	assert.ErrorIs(t, service.CommitReservation(expired, Customer{ID: "123"}), ErrReservationExpired)
+	assert.Equal(t, eur(20), campaigns.campaigns["c1"].DiscountSpent)
+
+	// Expired reservations are refunded by the next application
+	_, err := service.ApplyCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "123"})
+	assert.NoError(t, err)
+	assert.Equal(t, eur(10), campaigns.campaigns["c1"].DiscountSpent)
+	assert.Equal(t, 1, campaigns.campaigns["c1"].Redemptions)
+	assert.ErrorIs(t, service.CommitReservation(late, Customer{ID: "456"}), ErrReservationNotFound)
+	assert.NotContains(t, ledger.reservations, swept)
+}
+
+func TestService_ApplyCoupons_Campaign(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEN"] = &Coupon{Code: "TEN", Status: CouponActive, Currency: "EUR", Discount: 10, Stackable: true}
+	repo.coupons["FIVE"] = &Coupon{Code: "FIVE", Status: CouponActive, Currency: "EUR", Discount: 5, Stackable: true, CampaignID: "c1"}
+	ledger := newMockLedger()
+	campaigns := newMockCampaignRepository()
+	campaigns.Save(Campaign{ID: "c1", Name: "Summer", Currency: "EUR", Paused: true})
+
+	service := New(repo, ledger, campaigns, newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	result, err := service.ApplyCoupons(Basket{Value: eur(100)}, []string{"TEN", "FIVE"}, Customer{ID: "123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []AppliedCoupon{{Code: "TEN", DiscountAmount: eur(10)}}, result.Applied)
+	assertDropped(t, []DroppedCoupon{{Code: "FIVE", Detail: "campaign paused", Err: ErrCampaignPaused}}, result.Dropped)
+	assert.Equal(t, 0, campaigns.campaigns["c1"].Redemptions)
+}
+
+func TestService_ApplyCoupon_Rule(t *testing.T) {
+	shoes := Basket{Items: []LineItem{
+		{SKU: "SHOE-1", Category: "shoes", UnitPrice: eur(50), Quantity: 2},
+	}}
+
+	tests := []struct {
+		name        string
+		rule        string
+		basket      Basket
+		customer    Customer
+		history     []Redemption
+		expectedErr error
+	}{
+		{
+			name:     "role matches",
+			rule:     `customer.role == "vip"`,
+			basket:   Basket{Value: eur(100)},
+			customer: Customer{ID: "123", Role: "vip"},
+		},
+		{
+			name:        "role does not match",
+			rule:        `customer.role == "vip"`,
+			basket:      Basket{Value: eur(100)},
+			customer:    Customer{ID: "123", Role: "user"},
+			expectedErr: ErrRuleNotSatisfied,
+		},
+		{
+			name:     "basket contains category",
+			rule:     `basket.categories contains "shoes" && basket.quantity >= 2`,
+			basket:   shoes,
+			customer: Customer{ID: "123"},
+		},
+		{
+			name:     "new customer",
+			rule:     "customer.new",
+			basket:   Basket{Value: eur(100)},
+			customer: Customer{ID: "123"},
+			history:  []Redemption{{Code: "OTHER", CustomerID: "456"}},
+		},
+		{
+			name:        "returning customer",
+			rule:        "customer.new",
+			basket:      Basket{Value: eur(100)},
+			customer:    Customer{ID: "123"},
+			history:     []Redemption{{Code: "OTHER", CustomerID: "123"}},
+			expectedErr: ErrRuleNotSatisfied,
+		},
+		{
+			name:        "new customer rule without customer",
+			rule:        "customer.new",
+			basket:      Basket{Value: eur(100)},
+			expectedErr: ErrCustomerRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			repo.coupons["RULE10"] = &Coupon{Code: "RULE10", Status: CouponActive, Currency: "EUR", Discount: 10, Rule: tt.rule}
+			ledger := newMockLedger()
+			ledger.redemptions = tt.history
+
+			service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			result, err := service.ApplyCoupon(tt.basket, "RULE10", tt.customer)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+				assert.Len(t, ledger.redemptions, len(tt.history))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, eur(10), result.DiscountAmount)
+		})
+	}
+}
+
+func TestService_CreateCoupon_InvalidRule(t *testing.T) {
+	repo := newMockRepository()
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	err := service.CreateCoupon(Coupon{Code: "RULE10", Discount: 10, Rule: "customer.age > 18"}, testActor)
+
+	assert.ErrorIs(t, err, ErrInvalidCoupon)
+	assert.Contains(t, err.Error(), `unknown variable "customer.age"`)
+	assert.Empty(t, repo.coupons)
+}
+
+func TestService_ApplyCoupon_ExchangeRate(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	pln := func(amount int64) Money { return Money{Amount: amount, Currency: "PLN"} }
+	eurPln := RateTable{
+		ValidFrom: now.Add(-24 * time.Hour),
+		Rates:     []ExchangeRate{{From: "EUR", To: "PLN", Rate: "4.3125"}},
+	}
+
+	tests := []struct {
+		name           string
+		coupon         Coupon
+		rates          []RateTable
+		basket         Basket
+		expectedErr    string
+		expectDiscount Money
+		expectRate     *ExchangeRate
+	}{
+		{
+			name:           "explicit amount without rates",
+			coupon:         Coupon{Type: DiscountFixedAmount, Discount: 10, Currency: "EUR", Amounts: map[string]Money{"PLN": pln(4000)}},
+			basket:         Basket{Currency: "PLN", Value: pln(20000)},
+			expectDiscount: pln(4000),
+		},
+		{
+			name:           "fixed amount converted",
+			coupon:         Coupon{Type: DiscountFixedAmount, Discount: 10, Currency: "EUR"},
+			rates:          []RateTable{eurPln},
+			basket:         Basket{Currency: "PLN", Value: pln(20000)},
+			expectDiscount: pln(4313),
+			expectRate:     &ExchangeRate{Version: 1, From: "EUR", To: "PLN", Rate: "4.3125", ValidFrom: eurPln.ValidFrom},
+		},
+		{
+			name:   "inverse rate",
+			coupon: Coupon{Type: DiscountFixedAmount, Discount: 10, Currency: "EUR"},
+			rates: []RateTable{{
+				ValidFrom: now.Add(-time.Hour),
+				Rates:     []ExchangeRate{{From: "PLN", To: "EUR", Rate: "0.25"}},
+			}},
+			basket:         Basket{Currency: "PLN", Value: pln(20000)},
+			expectDiscount: pln(4000),
+			expectRate:     &ExchangeRate{Version: 1, From: "PLN", To: "EUR", Rate: "0.25", ValidFrom: now.Add(-time.Hour)},
+		},
+		{
+			name:   "rate in effect at application time",
+			coupon: Coupon{Type: DiscountFixedAmount, Discount: 10, Currency: "EUR"},
+			rates: []RateTable{eurPln, {
+				ValidFrom: now.Add(time.Hour),
+				Rates:     []ExchangeRate{{From: "EUR", To: "PLN", Rate: "5"}},
+			}},
+			basket:         Basket{Currency: "PLN", Value: pln(20000)},
+			expectDiscount: pln(4313),
+			expectRate:     &ExchangeRate{Version: 1, From: "EUR", To: "PLN", Rate: "4.3125", ValidFrom: eurPln.ValidFrom},
+		},
+		{
+			name:        "minimum basket value converted",
+			coupon:      Coupon{Discount: 10, Currency: "EUR", MinBasketValue: eur(50)},
+			rates:       []RateTable{eurPln},
+			basket:      Basket{Currency: "PLN", Value: pln(20000)},
+			expectedErr: "basket value below coupon minimum: got 200.00, want minimum 215.63",
+		},
+		{
+			name:        "no rate for the currency",
+			coupon:      Coupon{Type: DiscountFixedAmount, Discount: 10, Currency: "EUR"},
+			rates:       []RateTable{eurPln},
+			basket:      Basket{Currency: "GBP", Value: Money{Amount: 20000, Currency: "GBP"}},
+			expectedErr: "currency mismatch: coupon in EUR, basket in GBP: no exchange rate from EUR to GBP in version 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			tt.coupon.Code, tt.coupon.Status = "FX10", CouponActive
+			repo.coupons["FX10"] = &tt.coupon
+
+			service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(tt.rates...), newMockAuditLog(), newMockCouponHistory())
+			service.now = func() time.Time { return now }
+
+			result, err := service.ApplyCoupon(tt.basket, "FX10", Customer{})
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectDiscount, result.DiscountAmount)
+			assert.Equal(t, tt.expectRate, result.ExchangeRate)
+		})
+	}
+}
+
+func TestService_ApplyCoupon_CampaignChargedInCampaignCurrency(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	repo := newMockRepository()
+	repo.coupons["FX10"] = &Coupon{Code: "FX10", Status: CouponActive, Type: DiscountFixedAmount, Discount: 10, Currency: "EUR", CampaignID: "c1"}
+	campaigns := newMockCampaignRepository()
+	campaigns.Save(Campaign{ID: "c1", Name: "Summer", Currency: "EUR", DiscountSpent: eur(0)})
+	rates := newMockRateRepository(RateTable{
+		ValidFrom: now.Add(-time.Hour),
+		Rates:     []ExchangeRate{{From: "EUR", To: "PLN", Rate: "4"}},
+	})
+
+	service := New(repo, newMockLedger(), campaigns, rates, newMockAuditLog(), newMockCouponHistory())
+	service.now = func() time.Time { return now }
+
+	result, err := service.ApplyCoupon(Basket{Currency: "PLN", Value: Money{Amount: 20000, Currency: "PLN"}}, "FX10", Customer{})
+	assert.NoError(t, err)
+	assert.Equal(t, Money{Amount: 4000, Currency: "PLN"}, result.DiscountAmount)
+	assert.Equal(t, eur(10), campaigns.campaigns["c1"].DiscountSpent)
+}
+
+func TestService_LoadRates(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		rates       []ExchangeRate
+		expectedErr string
+	}{
+		{name: "valid", rates: []ExchangeRate{{From: "EUR", To: "PLN", Rate: "4.31"}, {From: "EUR", To: "GBP", Rate: "0.85"}}},
+		{name: "no rates", expectedErr: "invalid exchange rate: no rates"},
+		{name: "unknown currency", rates: []ExchangeRate{{From: "EUR", To: "XXX", Rate: "1"}}, expectedErr: `unknown currency "XXX"`},
+		{name: "same currency", rates: []ExchangeRate{{From: "EUR", To: "EUR", Rate: "1"}}, expectedErr: "rate from EUR to itself"},
+		{name: "not positive", rates: []ExchangeRate{{From: "EUR", To: "PLN", Rate: "0"}}, expectedErr: `invalid exchange rate "0"`},
+		{name: "not a number", rates: []ExchangeRate{{From: "EUR", To: "PLN", Rate: "abc"}}, expectedErr: `invalid exchange rate "abc"`},
+		{
+			name:        "both directions",
+			rates:       []ExchangeRate{{From: "EUR", To: "PLN", Rate: "4.31"}, {From: "PLN", To: "EUR", Rate: "0.23"}},
+			expectedErr: "duplicate rate for PLN/EUR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := New(newMockRepository(), newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			service.now = func() time.Time { return now }
+
+			table, err := service.LoadRates(RateTable{Rates: tt.rates})
+
+			if tt.expectedErr != "" {
+				assert.ErrorIs(t, err, ErrInvalidRate)
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, table.Version)
+			assert.Equal(t, now, table.ValidFrom)
+		})
+	}
+}
+
+func TestService_CreateCoupon_Amounts(t *testing.T) {
+	tests := []struct {
+		name        string
+		coupon      Coupon
+		expectedErr string
+	}{
+		{
+			name:   "valid",
+			coupon: Coupon{Code: "FX10", Type: DiscountFixedAmount, Discount: 10, Amounts: map[string]Money{"PLN": {Amount: 4000, Currency: "PLN"}}},
+		},
+		{
+			name:        "percentage",
+			coupon:      Coupon{Code: "FX10", Discount: 10, Amounts: map[string]Money{"PLN": {Amount: 4000, Currency: "PLN"}}},
+			expectedErr: "only allowed for fixed amount discounts",
+		},
+		{
+			name:        "coupon currency",
+			coupon:      Coupon{Code: "FX10", Type: DiscountFixedAmount, Discount: 10, Amounts: map[string]Money{"EUR": eur(5)}},
+			expectedErr: "amount for EUR duplicates the discount",
+		},
+		{
+			name:        "not positive",
+			coupon:      Coupon{Code: "FX10", Type: DiscountFixedAmount, Discount: 10, Amounts: map[string]Money{"PLN": {Currency: "PLN"}}},
+			expectedErr: "amount for PLN must be positive",
+		},
+		{
+			name:        "amount out of range",
+			coupon:      Coupon{Code: "FX10", Type: DiscountFixedAmount, Discount: 10, Amounts: map[string]Money{"PLN": {Amount: MaxAmount + 1, Currency: "PLN"}}},
+			expectedErr: "amount for PLN must be at most",
+		},
+		{
+			name:        "fixed amount out of range",
+			coupon:      Coupon{Code: "FX10", Type: DiscountFixedAmount, Discount: 92233720368547759},
+			expectedErr: "fixed amount discount must be at most",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := New(newMockRepository(), newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			err := service.CreateCoupon(tt.coupon, testActor)
+
+			if tt.expectedErr != "" {
+				assert.ErrorIs(t, err, ErrInvalidCoupon)
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_CouponLifecycle(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       CouponStatus
+		change       func(*Service, string, Actor) (*Coupon, error)
+		expectedErr  error
+		expectStatus CouponStatus
+	}{
+		{name: "activate draft", status: CouponDraft, change: (*Service).ActivateCoupon, expectStatus: CouponActive},
+		{name: "pause active", status: CouponActive, change: (*Service).PauseCoupon, expectStatus: CouponPaused},
+		{name: "resume paused", status: CouponPaused, change: (*Service).ResumeCoupon, expectStatus: CouponActive},
+		{name: "archive paused", status: CouponPaused, change: (*Service).ArchiveCoupon, expectStatus: CouponArchived},
+		{name: "activate active", status: CouponActive, change: (*Service).ActivateCoupon, expectedErr: ErrInvalidTransition, expectStatus: CouponActive},
+		{name: "resume draft", status: CouponDraft, change: (*Service).ResumeCoupon, expectedErr: ErrInvalidTransition, expectStatus: CouponDraft},
+		{name: "resume archived", status: CouponArchived, change: (*Service).ResumeCoupon, expectedErr: ErrInvalidTransition, expectStatus: CouponArchived},
+		{name: "archive archived", status: CouponArchived, change: (*Service).ArchiveCoupon, expectedErr: ErrInvalidTransition, expectStatus: CouponArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Discount: 10, Status: tt.status, Currency: "EUR"}
+			service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+			coupon, err := tt.change(service, "TEST10", testActor)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, coupon)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectStatus, coupon.Status)
+			}
+			assert.Equal(t, tt.expectStatus, repo.coupons["TEST10"].Status)
+		})
+	}
+}
+
+func TestService_ApplyCoupon_Status(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      CouponStatus
+		expectedErr string
+	}{
+		{name: "active", status: CouponActive},
+		{name: "draft", status: CouponDraft, expectedErr: "coupon not active: coupon is draft"},
+		{name: "paused", status: CouponPaused, expectedErr: "coupon not active: coupon is paused"},
+		{name: "archived", status: CouponArchived, expectedErr: "coupon not active: coupon is archived"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Discount: 10, Status: tt.status, Currency: "EUR"}
+			ledger := newMockLedger()
+			service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+			result, err := service.ApplyCoupon(Basket{Value: eur(100)}, "TEST10", Customer{})
+
+			if tt.expectedErr != "" {
+				assert.ErrorIs(t, err, ErrCouponNotActive)
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+				assert.Empty(t, ledger.redemptions)
+				return
+			}
 			assert.NoError(t, err)
-			assert.Equal(t, tt.discount, saved.Discount)
-			assert.Equal(t, tt.minBasketValue, saved.MinBasketValue)
+			assert.Len(t, ledger.redemptions, 1)
 		})
 	}
 }
 
-func TestService_GetCoupons(t *testing.T) {
+func TestService_CreateCoupon_Status(t *testing.T) {
+	repo := newMockRepository()
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	assert.NoError(t, service.CreateCoupon(Coupon{Code: "LIVE", Discount: 10}, testActor))
+	assert.Equal(t, CouponActive, repo.coupons["LIVE"].Status)
+
+	assert.NoError(t, service.CreateCoupon(Coupon{Code: "LATER", Discount: 10, Status: CouponDraft}, testActor))
+	assert.Equal(t, CouponDraft, repo.coupons["LATER"].Status)
+
+	err := service.CreateCoupon(Coupon{Code: "GONE", Discount: 10, Status: CouponArchived}, testActor)
+	assert.ErrorIs(t, err, ErrInvalidCoupon)
+}
+
+func TestService_UpdateCoupon(t *testing.T) {
 	tests := []struct {
 		name        string
-		codes       []string
-		setupRepo   func(*mockRepository)
-		expectedErr string
-		expectCount int
+		update      Coupon
+		version     int
+		expectedErr error
+		expect      *Coupon
 	}{
 		{
-			name:  "successful multiple coupons retrieval",
-			codes: []string{"CODE1", "CODE2"},
-			setupRepo: func(m *mockRepository) {
-				m.coupons["CODE1"] = &Coupon{Code: "CODE1", Discount: 10}
-				m.coupons["CODE2"] = &Coupon{Code: "CODE2", Discount: 20}
+			name:    "current version",
+			update:  Coupon{Code: "IGNORED", Discount: 15, Status: CouponArchived},
+			version: 2,
+			expect: &Coupon{
+				ID: "id-1", Code: "TEST10", Status: CouponPaused, Version: 3, Type: DiscountPercentage,
+				Discount: 15, Currency: "EUR", MinBasketValue: Money{Currency: "EUR"},
 			},
-			expectCount: 2,
 		},
 		{
-			name:  "one invalid code",
-			codes: []string{"CODE1", "INVALID"},
-			setupRepo: func(m *mockRepository) {
-				m.coupons["CODE1"] = &Coupon{Code: "CODE1", Discount: 10}
-			},
-			expectedErr: "error finding coupon INVALID",
+			name:        "stale version",
+			update:      Coupon{Discount: 15},
+			version:     1,
+			expectedErr: ErrVersionConflict,
 		},
 		{
-			name:        "empty codes list",
-			codes:       []string{},
-			setupRepo:   func(m *mockRepository) {},
-			expectCount: 0,
+			name:        "invalid terms",
+			update:      Coupon{Discount: 150},
+			version:     2,
+			expectedErr: ErrInvalidCoupon,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := newMockRepository()
-			tt.setupRepo(repo)
+			repo.coupons["TEST10"] = &Coupon{ID: "id-1", Code: "TEST10", Discount: 10, Status: CouponPaused, Currency: "EUR", Version: 2}
+			service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
 
-			service := New(repo)
-			coupons, err := service.GetCoupons(tt.codes)
+			coupon, err := service.UpdateCoupon("TEST10", tt.update, tt.version, testActor)
 
-			if tt.expectedErr != "" {
-				assert.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedErr)
-				assert.Nil(t, coupons)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, coupon)
+				assert.Equal(t, 10, repo.coupons["TEST10"].Discount)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, coupon)
+			assert.Equal(t, tt.expect, repo.coupons["TEST10"])
+		})
+	}
+}
+
+func TestService_DeleteCoupon(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10, Version: 2}
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	assert.ErrorIs(t, service.DeleteCoupon("TEST10", 1, testActor), ErrVersionConflict)
+	assert.Contains(t, repo.coupons, "TEST10")
+
+	assert.NoError(t, service.DeleteCoupon("TEST10", 2, testActor))
+	assert.NotContains(t, repo.coupons, "TEST10")
+}
+
+func TestService_CreateCoupon_Duplicate(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Status: CouponActive, Currency: "EUR", ID: "live", Code: "TEST10", Discount: 10, CampaignID: "c1"}
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	err := service.CreateCoupon(Coupon{Code: "TEST10", Discount: 50}, testActor)
+
+	assert.ErrorIs(t, err, ErrCouponExists)
+	assert.Equal(t, "live", repo.coupons["TEST10"].ID)
+	assert.Equal(t, 10, repo.coupons["TEST10"].Discount)
+}
+
+func TestService_ListCoupons(t *testing.T) {
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := newMockRepository()
+	for _, coupon := range []Coupon{
+		{Code: "SUMMER-1", Status: CouponActive, Type: DiscountPercentage, Discount: 10, ExpiresAt: day.Add(48 * time.Hour)},
+		{Code: "SUMMER-2", Status: CouponPaused, Type: DiscountPercentage, Discount: 20, CampaignID: "c1"},
+		{Code: "SUMMER-3", Status: CouponActive, Type: DiscountFixedAmount, Discount: 5, ExpiresAt: day, CampaignID: "c1"},
+		{Code: "WINTER-1", Status: CouponActive, Type: DiscountPercentage, Discount: 15, ExpiresAt: day.Add(24 * time.Hour)},
+	} {
+		repo.coupons[coupon.Code] = &coupon
+	}
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	tests := []struct {
+		name        string
+		query       CouponQuery
+		expectPages [][]string
+	}{
+		{
+			name:        "all by code",
+			query:       CouponQuery{Limit: 3},
+			expectPages: [][]string{{"SUMMER-1", "SUMMER-2", "SUMMER-3"}, {"WINTER-1"}},
+		},
+		{
+			name:        "by expiry, never expiring last",
+			query:       CouponQuery{Sort: SortByExpiry, Limit: 2},
+			expectPages: [][]string{{"SUMMER-3", "WINTER-1"}, {"SUMMER-1", "SUMMER-2"}},
+		},
+		{
+			name:        "by discount descending",
+			query:       CouponQuery{Sort: SortByDiscount, Descending: true, Limit: 1},
+			expectPages: [][]string{{"SUMMER-2"}, {"WINTER-1"}, {"SUMMER-1"}, {"SUMMER-3"}},
+		},
+		{
+			name:        "filters",
+			query:       CouponQuery{Status: CouponActive, CodePrefix: "SUMMER-", ExpiresBefore: day.Add(72 * time.Hour)},
+			expectPages: [][]string{{"SUMMER-1", "SUMMER-3"}},
+		},
+		{
+			name:        "campaign and type",
+			query:       CouponQuery{CampaignID: "c1", Type: DiscountFixedAmount},
+			expectPages: [][]string{{"SUMMER-3"}},
+		},
+		{
+			name:        "no match",
+			query:       CouponQuery{CodePrefix: "SPRING-"},
+			expectPages: [][]string{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages [][]string
+			cursor := ""
+			for {
+				page, err := service.ListCoupons(tt.query, cursor)
+				assert.NoError(t, err)
+
+				codes := []string{}
+				for _, coupon := range page.Coupons {
+					codes = append(codes, coupon.Code)
+				}
+				pages = append(pages, codes)
+
+				if page.NextCursor == "" || len(pages) > len(tt.expectPages) {
+					break
+				}
+				cursor = page.NextCursor
+			}
+			assert.Equal(t, tt.expectPages, pages)
+		})
+	}
+}
+
+func TestService_ListCoupons_Invalid(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["A"] = &Coupon{Status: CouponActive, Currency: "EUR", Code: "A"}
+	repo.coupons["B"] = &Coupon{Status: CouponActive, Currency: "EUR", Code: "B"}
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	page, err := service.ListCoupons(CouponQuery{Limit: 1}, "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, page.NextCursor)
+
+	_, err = service.ListCoupons(CouponQuery{Limit: 1, Sort: SortByDiscount}, page.NextCursor)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = service.ListCoupons(CouponQuery{}, "not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = service.ListCoupons(CouponQuery{Sort: "name"}, "")
+	assert.ErrorContains(t, err, `unknown sort "name"`)
+
+	_, err = service.ListCoupons(CouponQuery{Limit: MaxPageSize + 1}, "")
+	assert.ErrorContains(t, err, "limit must be at most")
+}
+
+func TestService_ErrorKinds(t *testing.T) {
+	repo := newMockRepository()
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	_, err := service.GetCoupon("MISSING")
+	assert.ErrorIs(t, err, ErrCouponNotFound)
+
+	err = service.CreateCoupon(Coupon{Code: "", Discount: 10}, testActor)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	_, err = service.ListCoupons(CouponQuery{Limit: MaxPageSize + 1}, "")
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+func TestService_ValidateCoupon(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	all := Checks
+
+	tests := []struct {
+		name         string
+		code         string
+		basket       Basket
+		customer     Customer
+		history      []Redemption
+		expectChecks []string
+		expectLast   EligibilityCheck
+		expectedErr  error
+	}{
+		{
+			name:         "eligible",
+			code:         "TEST10",
+			basket:       Basket{Value: eur(100)},
+			customer:     Customer{ID: "123", Role: "vip"},
+			expectChecks: all,
+			expectLast:   EligibilityCheck{Name: CheckCampaign, Passed: true, Actual: "c1"},
+		},
+		{
+			name:         "unknown code",
+			code:         "MISSING",
+			basket:       Basket{Value: eur(100)},
+			expectChecks: all[:1],
+			expectLast:   EligibilityCheck{Name: CheckExists, Actual: "MISSING", Detail: "coupon not found"},
+			expectedErr:  ErrCouponNotFound,
+		},
+		{
+			name:         "not assigned to customer",
+			code:         "TEST10",
+			basket:       Basket{Value: eur(100)},
+			customer:     Customer{ID: "456", Role: "vip"},
+			expectChecks: all[:4],
+			expectLast: EligibilityCheck{Name: CheckCustomer, Actual: "456", Expected: "assigned customer",
+				Detail: "coupon not assigned to customer"},
+			expectedErr: ErrCouponNotAssigned,
+		},
+		{
+			name:         "below minimum",
+			code:         "TEST10",
+			basket:       Basket{Value: eur(40)},
+			customer:     Customer{ID: "123", Role: "vip"},
+			expectChecks: all[:7],
+			expectLast: EligibilityCheck{Name: CheckMinBasket, Actual: "40.00 EUR", Expected: ">= 50.00 EUR",
+				Detail: "basket value below coupon minimum: got 40.00, want minimum 50.00"},
+			expectedErr: ErrBelowMinBasketValue,
+		},
+		{
+			name:         "customer limit reached",
+			code:         "TEST10",
+			basket:       Basket{Value: eur(100)},
+			customer:     Customer{ID: "123", Role: "vip"},
+			history:      []Redemption{{Code: "TEST10", CustomerID: "123"}},
+			expectChecks: all[:8],
+			expectLast: EligibilityCheck{Name: CheckLimits, Actual: "1 redeemed, 1 by customer", Expected: "< 5 total, < 1 by customer",
+				Detail: "coupon redemption limit reached for customer"},
+			expectedErr: ErrCustomerLimitReached,
+		},
+		{
+			name:         "rule not met",
+			code:         "TEST10",
+			basket:       Basket{Value: eur(100)},
+			customer:     Customer{ID: "123", Role: "user"},
+			expectChecks: all[:9],
+			expectLast: EligibilityCheck{Name: CheckRule, Actual: `customer.role="user"`, Expected: `customer.role == "vip"`,
+				Detail: "coupon conditions not met"},
+			expectedErr: ErrRuleNotSatisfied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			repo.coupons["TEST10"] = &Coupon{
+				Code: "TEST10", Status: CouponActive, Discount: 10, Currency: "EUR", MinBasketValue: eur(50), CampaignID: "c1",
+				MaxRedemptions: 5, MaxPerCustomer: 1, Rule: `customer.role == "vip"`,
+				StartsAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour),
+				CustomerIDs: []string{"123"},
+			}
+			ledger := newMockLedger()
+			ledger.redemptions = tt.history
+			campaigns := newMockCampaignRepository()
+			campaigns.Save(Campaign{ID: "c1", Name: "Summer", Currency: "EUR", DiscountBudget: eur(100)})
+			service := New(repo, ledger, campaigns, newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+			service.now = func() time.Time { return now }
+
+			result := service.ValidateCoupon(tt.basket, tt.code, tt.customer)
+
+			var ran []string
+			for i, check := range result.Checks {
+				assert.Equal(t, all[i], check.Name)
+				if check.Skipped {
+					assert.Equal(t, EligibilityCheck{Name: check.Name, Skipped: true}, check)
+					continue
+				}
+				ran = append(ran, check.Name)
+				if i < len(tt.expectChecks)-1 {
+					assert.True(t, check.Passed, check.Name)
+				}
+			}
+			assert.Len(t, result.Checks, len(all))
+			assert.Equal(t, tt.expectChecks, ran)
+			assert.Equal(t, tt.expectLast, result.Checks[len(ran)-1])
+			assert.Equal(t, tt.expectedErr == nil, result.Eligible)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, result.Err, tt.expectedErr)
+				assert.Nil(t, result.Basket)
+			} else {
+				assert.NoError(t, result.Err)
+				assert.Equal(t, eur(10), result.Basket.DiscountAmount)
+			}
+
+			assert.Equal(t, tt.history, ledger.redemptions)
+			campaign, _ := campaigns.FindByID("c1")
+			assert.True(t, campaign.DiscountSpent.IsZero())
+		})
+	}
+}
+
+func TestService_ValidateCoupon_MatchesApply(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEST10"] = &Coupon{Code: "TEST10", Status: CouponActive, Currency: "EUR", Discount: 10, MinBasketValue: eur(50)}
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	for _, value := range []float64{40, 50, 100} {
+		basket := Basket{Value: eur(value)}
+		result := service.ValidateCoupon(basket, "TEST10", Customer{})
+		applied, err := service.ApplyCoupon(basket, "TEST10", Customer{})
+
+		assert.Equal(t, err, result.Err)
+		assert.Equal(t, applied, result.Basket)
+	}
+}
+
+func TestService_ApplicableCoupons(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := newMockRepository()
+	repo.coupons["TEN"] = &Coupon{Code: "TEN", Status: CouponActive, Currency: "EUR", Type: DiscountPercentage, Discount: 10}
+	repo.coupons["FIVE"] = &Coupon{Code: "FIVE", Status: CouponActive, Currency: "EUR", Type: DiscountFixedAmount, Discount: 5, Stackable: true}
+	repo.coupons["TWENTY"] = &Coupon{Code: "TWENTY", Status: CouponActive, Currency: "EUR", Type: DiscountPercentage, Discount: 20, ExpiresAt: now.Add(time.Hour)}
+	repo.coupons["BIG"] = &Coupon{Code: "BIG", Status: CouponActive, Currency: "EUR", Discount: 50, MinBasketValue: eur(500)}
+	repo.coupons["VIP"] = &Coupon{Code: "VIP", Status: CouponActive, Currency: "EUR", Discount: 30, Rule: `customer.role == "vip"`}
+	repo.coupons["PAUSED"] = &Coupon{Code: "PAUSED", Discount: 40, Status: CouponPaused, Currency: "EUR"}
+	repo.coupons["OLD"] = &Coupon{Code: "OLD", Status: CouponActive, Currency: "EUR", Discount: 40, ExpiresAt: now.Add(-time.Hour)}
+	repo.coupons["USED"] = &Coupon{Code: "USED", Status: CouponActive, Currency: "EUR", Discount: 40, MaxPerCustomer: 1}
+	ledger := newMockLedger()
+	ledger.redemptions = []Redemption{{Code: "USED", CustomerID: "123"}}
+	service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	service.now = func() time.Time { return now }
+
+	applicable, err := service.ApplicableCoupons(Basket{Value: eur(100)}, Customer{ID: "123", Role: "user"})
+
+	assert.NoError(t, err)
+	var codes []string
+	for _, coupon := range applicable {
+		codes = append(codes, coupon.Code)
+	}
+	assert.Equal(t, []string{"TWENTY", "TEN", "FIVE"}, codes)
+	assert.Equal(t, eur(20), applicable[0].DiscountAmount)
+	assert.Equal(t, eur(80), applicable[0].FinalValue)
+	assert.Equal(t, now.Add(time.Hour), *applicable[0].ExpiresAt)
+	assert.True(t, applicable[2].Stackable)
+	assert.Len(t, ledger.redemptions, 1)
+
+	applicable, err = service.ApplicableCoupons(Basket{Value: eur(100)}, Customer{ID: "456", Role: "vip"})
+	assert.NoError(t, err)
+	assert.Len(t, applicable, 5)
+	assert.Equal(t, "USED", applicable[0].Code)
+
+	_, err = service.ApplicableCoupons(Basket{}, Customer{})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	repo.err = fmt.Errorf("database error")
+	_, err = service.ApplicableCoupons(Basket{Value: eur(100)}, Customer{})
+	assert.ErrorContains(t, err, "database error")
+}
+
+func TestService_RecommendCoupons(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEN"] = &Coupon{Code: "TEN", Status: CouponActive, Currency: "EUR", Discount: 10, Stackable: true, Priority: 1}
+	repo.coupons["FIVE"] = &Coupon{Code: "FIVE", Status: CouponActive, Currency: "EUR", Type: DiscountFixedAmount, Discount: 5, Stackable: true}
+	repo.coupons["SOLO12"] = &Coupon{Code: "SOLO12", Status: CouponActive, Currency: "EUR", Discount: 12}
+	repo.coupons["BIG"] = &Coupon{Code: "BIG", Status: CouponActive, Currency: "EUR", Discount: 50, MinBasketValue: eur(500)}
+	ledger := newMockLedger()
+	service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	result, err := service.RecommendCoupons(Basket{Value: eur(100)}, []string{"SOLO12", "FIVE", "TEN", "BIG"}, Customer{ID: "123"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TEN", "FIVE"}, result.Codes)
+	assert.Equal(t, eur(15), result.Savings)
+	assert.Equal(t, eur(85), result.Basket.FinalValue)
+	assert.Equal(t, []CouponSet{
+		{Codes: []string{"SOLO12"}, Savings: eur(12), FinalValue: eur(88)},
+		{Codes: []string{"TEN"}, Savings: eur(10), FinalValue: eur(90)},
+		{Codes: []string{"FIVE"}, Savings: eur(5), FinalValue: eur(95)},
+	}, result.Alternatives)
+	assert.Len(t, result.Dropped, 1)
+	assert.Equal(t, "BIG", result.Dropped[0].Code)
+	assert.Empty(t, ledger.redemptions)
+	assert.Empty(t, ledger.reservations)
+
+	// The recommendation is what applying the same codes grants
+	applied, err := service.ApplyCoupons(Basket{Value: eur(100)}, []string{"SOLO12", "FIVE", "TEN", "BIG"}, Customer{ID: "123"})
+	assert.NoError(t, err)
+	assert.Equal(t, result.Basket, applied.Basket)
+}
+
+func TestService_RecommendCoupons_NoneValid(t *testing.T) {
+	service := New(newMockRepository(), newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	result, err := service.RecommendCoupons(Basket{Value: eur(100)}, []string{"MISSING"}, Customer{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Codes)
+	assert.True(t, result.Savings.IsZero())
+	assert.Equal(t, eur(100), result.Basket.FinalValue)
+	assert.Empty(t, result.Alternatives)
+	assertDropped(t, []DroppedCoupon{{Code: "MISSING", Detail: "coupon not found", Err: ErrCouponNotFound}}, result.Dropped)
+}
+
+// assertDropped compares dropped coupons by code and detail, matching
+// their errors with errors.Is since the service wraps them.
+func assertDropped(t *testing.T, expected, actual []DroppedCoupon) {
+	t.Helper()
+	if !assert.Len(t, actual, len(expected)) {
+		return
+	}
+	for i := range expected {
+		assert.Equal(t, expected[i].Code, actual[i].Code)
+		assert.Equal(t, expected[i].Detail, actual[i].Detail)
+		assert.ErrorIs(t, actual[i].Err, expected[i].Err)
+	}
+}
+
+func TestService_Audit(t *testing.T) {
+	repo := newMockRepository()
+	audit := newMockAuditLog()
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), audit, newMockCouponHistory())
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return at }
+	ops := Actor{ID: "ops-7", Role: "admin", RequestID: "req-2"}
+
+	assert.NoError(t, service.CreateCoupon(Coupon{Code: "TEST10", Discount: 10}, testActor))
+	_, err := service.UpdateCoupon("TEST10", Coupon{Discount: 15}, 1, ops)
+	assert.NoError(t, err)
+	_, err = service.PauseCoupon("TEST10", testActor)
+	assert.NoError(t, err)
+	assert.NoError(t, service.DeleteCoupon("TEST10", 3, ops))
+
+	// Failed mutations are not recorded
+	_, err = service.UpdateCoupon("TEST10", Coupon{Discount: 20}, 3, ops)
+	assert.ErrorIs(t, err, ErrCouponNotFound)
+
+	if !assert.Len(t, audit.entries, 4) {
+		return
+	}
+	created, updated, paused, deleted := audit.entries[0], audit.entries[1], audit.entries[2], audit.entries[3]
+
+	assert.Equal(t, AuditCreate, created.Action)
+	assert.Equal(t, "TEST10", created.Code)
+	assert.Equal(t, "admin-1", created.ActorID)
+	assert.Equal(t, "admin", created.ActorRole)
+	assert.Equal(t, "req-1", created.RequestID)
+	assert.Equal(t, at, created.At)
+	assert.Equal(t, 1, created.Version)
+	assert.NotEmpty(t, created.ID)
+	assert.Contains(t, created.Changes, FieldChange{Field: "Discount", After: 10})
+	assert.Contains(t, created.Changes, FieldChange{Field: "Status", After: CouponActive})
+	for _, change := range created.Changes {
+		assert.Nil(t, change.Before, change.Field)
+		assert.NotEqual(t, "Version", change.Field)
+	}
+
+	assert.Equal(t, AuditUpdate, updated.Action)
+	assert.Equal(t, "ops-7", updated.ActorID)
+	assert.Equal(t, "req-2", updated.RequestID)
+	assert.Equal(t, 2, updated.Version)
+	assert.Equal(t, []FieldChange{{Field: "Discount", Before: 10, After: 15}}, updated.Changes)
+
+	assert.Equal(t, AuditStatus, paused.Action)
+	assert.Equal(t, 3, paused.Version)
+	assert.Equal(t, []FieldChange{{Field: "Status", Before: CouponActive, After: CouponPaused}}, paused.Changes)
+
+	assert.Equal(t, AuditDelete, deleted.Action)
+	assert.Equal(t, 3, deleted.Version)
+	assert.Contains(t, deleted.Changes, FieldChange{Field: "Discount", Before: 15})
+	for _, change := range deleted.Changes {
+		assert.Nil(t, change.After, change.Field)
+	}
+}
+
+func TestService_GenerateCoupons_BatchFails(t *testing.T) {
+	repo := newMockRepository()
+	repo.failBatch = 2
+	audit := newMockAuditLog()
+	history := newMockCouponHistory()
+
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), audit, history)
+	codes, err := service.GenerateCoupons(Coupon{Discount: 10}, CodeSpec{Count: GenerationBatchSize + 1}, testActor)
+
+	assert.ErrorContains(t, err, "database error")
+	assert.Nil(t, codes)
+	assert.Empty(t, repo.coupons)
+	assert.Empty(t, audit.entries)
+	assert.Empty(t, history.versions)
+}
+
+func TestService_Audit_GenerateCoupons(t *testing.T) {
+	audit := newMockAuditLog()
+	service := New(newMockRepository(), newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), audit, newMockCouponHistory())
+
+	codes, err := service.GenerateCoupons(Coupon{Discount: 10}, CodeSpec{Count: 3, Prefix: "GEN-"}, testActor)
+	assert.NoError(t, err)
+
+	var audited []string
+	for _, entry := range audit.entries {
+		assert.Equal(t, AuditCreate, entry.Action)
+		assert.Equal(t, "admin-1", entry.ActorID)
+		audited = append(audited, entry.Code)
+	}
+	assert.Equal(t, codes, audited)
+}
+
+func TestService_Audit_AppendFails(t *testing.T) {
+	repo := newMockRepository()
+	audit := newMockAuditLog()
+	audit.err = fmt.Errorf("disk full")
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), audit, newMockCouponHistory())
+
+	err := service.CreateCoupon(Coupon{Code: "TEST10", Discount: 10}, testActor)
+
+	assert.ErrorContains(t, err, "audit: disk full")
+}
+
+func TestService_ListAudit(t *testing.T) {
+	audit := newMockAuditLog()
+	service := New(newMockRepository(), newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), audit, newMockCouponHistory())
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i, code := range []string{"A", "B", "A"} {
+		audit.entries = append(audit.entries, AuditEntry{ID: fmt.Sprint(i), Code: code, At: day.Add(time.Duration(i) * time.Hour)})
+	}
+
+	tests := []struct {
+		name        string
+		query       AuditQuery
+		expectedErr error
+		expectIDs   []string
+	}{
+		{name: "default limit, newest first", query: AuditQuery{}, expectIDs: []string{"2", "1", "0"}},
+		{name: "filtered", query: AuditQuery{Code: "A", Limit: 1}, expectIDs: []string{"2"}},
+		{name: "no match", query: AuditQuery{Code: "C"}, expectIDs: []string{}},
+		{name: "limit too large", query: AuditQuery{Limit: MaxAuditPageSize + 1}, expectedErr: ErrInvalidRequest},
+		{name: "empty time range", query: AuditQuery{From: day, To: day}, expectedErr: ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := service.ListAudit(tt.query)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
 				return
 			}
+			assert.NoError(t, err)
+			ids := []string{}
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.expectIDs, ids)
+		})
+	}
+}
+
+func TestService_CouponHistory(t *testing.T) {
+	repo := newMockRepository()
+	ledger := newMockLedger()
+	history := newMockCouponHistory()
+	service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), history)
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return day.Add(time.Duration(hours) * time.Hour) }
 
+	service.now = func() time.Time { return at(0) }
+	assert.NoError(t, service.CreateCoupon(Coupon{Code: "TEST10", Discount: 10}, testActor))
+	id := repo.coupons["TEST10"].ID
+
+	service.now = func() time.Time { return at(1) }
+	result, err := service.ApplyCoupon(Basket{Value: eur(100)}, "TEST10", Customer{ID: "123"})
+	assert.NoError(t, err)
+	assert.Equal(t, id+"-v1", result.CouponVersionID)
+	assert.Equal(t, id+"-v1", ledger.redemptions[0].CouponVersionID)
+
+	service.now = func() time.Time { return at(2) }
+	_, err = service.UpdateCoupon("TEST10", Coupon{Discount: 15, MinBasketValue: eur(20)}, 1, testActor)
+	assert.NoError(t, err)
+	service.now = func() time.Time { return at(3) }
+	_, err = service.PauseCoupon("TEST10", testActor)
+	assert.NoError(t, err)
+
+	versions, err := service.ListCouponVersions("TEST10")
+	assert.NoError(t, err)
+	recorded := []time.Time{at(0), at(2), at(3)}
+	if assert.Len(t, versions, 3) {
+		for i, version := range versions {
+			assert.Equal(t, i+1, version.Version)
+			assert.Equal(t, i+1, version.Coupon.Version)
+			assert.Equal(t, fmt.Sprintf("%s-v%d", id, i+1), version.ID)
+			assert.Equal(t, recorded[i], version.RecordedAt)
+		}
+	}
+
+	// The terms a past application was granted by
+	version, err := service.GetCouponVersionByID(result.CouponVersionID)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, version.Coupon.Discount)
+
+	version, err = service.GetCouponVersion("TEST10", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 15, version.Coupon.Discount)
+	assert.Equal(t, CouponActive, version.Coupon.Status)
+
+	tests := []struct {
+		name          string
+		at            time.Time
+		expectedErr   error
+		expectVersion int
+	}{
+		{name: "before creation", at: at(-1), expectedErr: ErrCouponVersionNotFound},
+		{name: "at creation", at: at(0), expectVersion: 1},
+		{name: "between changes", at: at(1), expectVersion: 1},
+		{name: "at a change", at: at(2), expectVersion: 2},
+		{name: "after the last change", at: at(24), expectVersion: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := service.GetCouponAsOf("TEST10", tt.at)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
 			assert.NoError(t, err)
-			assert.Len(t, coupons, tt.expectCount)
-			for i, code := range tt.codes {
-				assert.Equal(t, code, coupons[i].Code)
+			assert.Equal(t, tt.expectVersion, version.Version)
+		})
+	}
+
+	diff, err := service.DiffCouponVersions("TEST10", 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, &CouponDiff{Code: "TEST10", From: 1, To: 3, Changes: []FieldChange{
+		{Field: "Status", Before: CouponActive, After: CouponPaused},
+		{Field: "Discount", Before: 10, After: 15},
+		{Field: "MinBasketValue", Before: eur(0), After: eur(20)},
+	}}, diff)
+
+	_, err = service.GetCouponVersion("TEST10", 4)
+	assert.ErrorIs(t, err, ErrCouponVersionNotFound)
+	_, err = service.DiffCouponVersions("TEST10", 1, 4)
+	assert.ErrorIs(t, err, ErrCouponVersionNotFound)
+	_, err = service.GetCouponVersionByID(id + "-v4")
+	assert.ErrorIs(t, err, ErrCouponVersionNotFound)
+	_, err = service.ListCouponVersions("MISSING")
+	assert.ErrorIs(t, err, ErrCouponNotFound)
+}
+
+func TestService_CouponHistory_Recreated(t *testing.T) {
+	repo := newMockRepository()
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	service.now = func() time.Time { return day }
+	assert.NoError(t, service.CreateCoupon(Coupon{Code: "TEST10", Discount: 10}, testActor))
+	service.now = func() time.Time { return day.Add(time.Hour) }
+	assert.NoError(t, service.DeleteCoupon("TEST10", 1, testActor))
+	service.now = func() time.Time { return day.Add(2 * time.Hour) }
+	assert.NoError(t, service.CreateCoupon(Coupon{Code: "TEST10", Discount: 20}, testActor))
+
+	// The code had no coupon between the deletion and its reuse
+	_, err := service.GetCouponAsOf("TEST10", day.Add(90*time.Minute))
+	assert.ErrorIs(t, err, ErrCouponVersionNotFound)
+	version, err := service.GetCouponAsOf("TEST10", day.Add(30*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 10, version.Coupon.Discount)
+	version, err = service.GetCouponAsOf("TEST10", day.Add(3*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 20, version.Coupon.Discount)
+
+	versions, err := service.ListCouponVersions("TEST10")
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 3) {
+		assert.True(t, versions[1].Deleted)
+		assert.Equal(t, 2, versions[1].Version)
+		assert.Equal(t, day.Add(time.Hour), versions[1].RecordedAt)
+		assert.Equal(t, 10, versions[1].Coupon.Discount)
+	}
+
+	version, err = service.GetCouponVersion("TEST10", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, version.Coupon.Discount)
+	assert.Equal(t, repo.coupons["TEST10"].ID+"-v1", version.ID)
+}
+
+func TestService_CouponHistory_GenerateCoupons(t *testing.T) {
+	history := newMockCouponHistory()
+	service := New(newMockRepository(), newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), history)
+
+	codes, err := service.GenerateCoupons(Coupon{Discount: 10}, CodeSpec{Count: 3, Prefix: "GEN-"}, testActor)
+	assert.NoError(t, err)
+
+	for _, code := range codes {
+		versions, err := service.ListCouponVersions(code)
+		assert.NoError(t, err)
+		assert.Len(t, versions, 1)
+	}
+}
+
+func TestService_ApplyCoupon_Personal(t *testing.T) {
+	tests := []struct {
+		name        string
+		customer    Customer
+		expectedErr error
+	}{
+		{name: "assigned customer", customer: Customer{ID: "123"}},
+		{name: "other assigned customer", customer: Customer{ID: "456"}},
+		{name: "customer not assigned", customer: Customer{ID: "789"}, expectedErr: ErrCouponNotAssigned},
+		{name: "no customer", customer: Customer{}, expectedErr: ErrCustomerRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			repo.coupons["VIP15"] = &Coupon{Code: "VIP15", Status: CouponActive, Currency: "EUR", Discount: 15, CustomerIDs: []string{"123", "456"}}
+			ledger := newMockLedger()
+			service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+			result, err := service.ApplyCoupon(Basket{Value: eur(100)}, "VIP15", tt.customer)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, ledger.redemptions)
+				return
 			}
+			assert.NoError(t, err)
+			assert.Equal(t, eur(85), result.FinalValue)
 		})
 	}
 }
+
+func TestService_CreateCoupon_Personal(t *testing.T) {
+	repo := newMockRepository()
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	err := service.CreateCoupon(Coupon{Code: "VIP15", Discount: 15, CustomerIDs: []string{"456", "123", "456"}}, testActor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"123", "456"}, repo.coupons["VIP15"].CustomerIDs)
+
+	err = service.CreateCoupon(Coupon{Code: "BAD", Discount: 15, CustomerIDs: []string{"123", ""}}, testActor)
+	assert.ErrorIs(t, err, ErrInvalidCoupon)
+}
+
+func TestService_ApplicableCoupons_Personal(t *testing.T) {
+	repo := newMockRepository()
+	repo.coupons["TEN"] = &Coupon{Code: "TEN", Status: CouponActive, Currency: "EUR", Discount: 10}
+	repo.coupons["MINE"] = &Coupon{Code: "MINE", Status: CouponActive, Currency: "EUR", Discount: 20, CustomerIDs: []string{"123"}}
+	repo.coupons["THEIRS"] = &Coupon{Code: "THEIRS", Status: CouponActive, Currency: "EUR", Discount: 30, CustomerIDs: []string{"456"}}
+	repo.coupons["PAUSED"] = &Coupon{Code: "PAUSED", Discount: 40, Status: CouponPaused, Currency: "EUR", CustomerIDs: []string{"123"}}
+	service := New(repo, newMockLedger(), newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+
+	codes := func(customer Customer) []string {
+		applicable, err := service.ApplicableCoupons(Basket{Value: eur(100)}, customer)
+		assert.NoError(t, err)
+		var codes []string
+		for _, coupon := range applicable {
+			codes = append(codes, coupon.Code)
+		}
+		return codes
+	}
+
+	assert.Equal(t, []string{"MINE", "TEN"}, codes(Customer{ID: "123"}))
+	assert.Equal(t, []string{"THEIRS", "TEN"}, codes(Customer{ID: "456"}))
+	assert.Equal(t, []string{"TEN"}, codes(Customer{}))
+}
+
+func TestService_CustomerCoupons(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := newMockRepository()
+	repo.coupons["PUBLIC"] = &Coupon{Code: "PUBLIC", Status: CouponActive, Currency: "EUR", Discount: 10}
+	repo.coupons["LATER"] = &Coupon{Code: "LATER", Status: CouponActive, Currency: "EUR", Discount: 15, CustomerIDs: []string{"123"}, StartsAt: now.Add(24 * time.Hour), ExpiresAt: now.Add(72 * time.Hour)}
+	repo.coupons["SOON"] = &Coupon{Code: "SOON", Status: CouponActive, Type: DiscountFixedAmount, Discount: 5, Currency: "EUR", CustomerIDs: []string{"123", "456"}, ExpiresAt: now.Add(time.Hour)}
+	repo.coupons["OPEN"] = &Coupon{Code: "OPEN", Status: CouponActive, Currency: "EUR", Discount: 20, CustomerIDs: []string{"123"}}
+	repo.coupons["EXPIRED"] = &Coupon{Code: "EXPIRED", Status: CouponActive, Currency: "EUR", Discount: 20, CustomerIDs: []string{"123"}, ExpiresAt: now}
+	repo.coupons["PAUSED"] = &Coupon{Code: "PAUSED", Discount: 20, Status: CouponPaused, Currency: "EUR", CustomerIDs: []string{"123"}}
+	repo.coupons["USED"] = &Coupon{Code: "USED", Status: CouponActive, Currency: "EUR", Discount: 20, CustomerIDs: []string{"123"}}
+	repo.coupons["TAKEN"] = &Coupon{Code: "TAKEN", Status: CouponActive, Currency: "EUR", Discount: 20, MaxRedemptions: 1, CustomerIDs: []string{"123", "456"}}
+	repo.coupons["THEIRS"] = &Coupon{Code: "THEIRS", Status: CouponActive, Currency: "EUR", Discount: 20, CustomerIDs: []string{"456"}}
+	ledger := newMockLedger()
+	ledger.redemptions = []Redemption{
+		{Code: "USED", CustomerID: "123"},
+		{Code: "TAKEN", CustomerID: "456"},
+	}
+	service := New(repo, ledger, newMockCampaignRepository(), newMockRateRepository(), newMockAuditLog(), newMockCouponHistory())
+	service.now = func() time.Time { return now }
+
+	coupons, err := service.CustomerCoupons(Customer{ID: "123"})
+
+	assert.NoError(t, err)
+	var codes []string
+	for _, coupon := range coupons {
+		codes = append(codes, coupon.Code)
+	}
+	assert.Equal(t, []string{"SOON", "LATER", "OPEN"}, codes)
+	soon := now.Add(time.Hour)
+	assert.Equal(t, AssignedCoupon{Code: "SOON", Type: DiscountFixedAmount, Discount: 5, Currency: "EUR", ExpiresAt: &soon}, coupons[0])
+
+	_, err = service.CustomerCoupons(Customer{})
+	assert.ErrorIs(t, err, ErrCustomerRequired)
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	. "reviewsch/internal/service/entity"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxStackedCodes is the maximum number of codes accepted by ApplyCoupons.
+// Every combination of the valid codes is evaluated, so the limit keeps
+// the search small.
+const MaxStackedCodes = 10
+
+// candidate is a coupon that can be applied to the basket on its own,
+// together with the basket it produces.
+type candidate struct {
+	coupon *Coupon
+	basket *Basket
+}
+
+// ApplyCoupons applies the combination of codes that gives the largest
+// valid discount and redeems every coupon in it. Codes that are invalid or
+// left out of the combination are reported with the reason they were
+// dropped.
+func (s *Service) ApplyCoupons(basket Basket, codes []string, customer Customer) (*Application, error) {
+	if err := normalizeBasket(&basket); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if err := s.refundExpired(now); err != nil {
+		return nil, err
+	}
+	candidates, dropped, err := s.candidates(basket, codes, customer, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		chosen, skipped := resolve(candidates)
+		application := combine(basket, chosen, append(slices.Clip(dropped), skipped...))
+		reservations, failed, err := s.holdAll(chosen, application.Applied, customer.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		if failed == nil {
+			campaignIDs := make([]string, 0, len(reservations))
+			for _, reservation := range reservations {
+				if _, err := s.ledger.Commit(reservation.ID, now); err != nil {
+					return nil, err
+				}
+				campaignIDs = append(campaignIDs, reservation.CampaignID)
+			}
+			if err := s.pauseExhausted(campaignIDs...); err != nil {
+				return nil, err
+			}
+			return application, nil
+		}
+
+		// A limit or budget was reached while redeeming: drop the coupon
+		// and look for the best combination without it.
+		dropped = append(dropped, *failed)
+		candidates = withoutCode(candidates, failed.Code)
+	}
+}
+
+// candidates evaluates every code on its own against the basket. Codes the
+// basket or customer does not qualify for are dropped; any other failure
+// is returned.
+func (s *Service) candidates(basket Basket, codes []string, customer Customer, now time.Time) ([]candidate, []DroppedCoupon, error) {
+	codes = uniqueCodes(codes)
+	if len(codes) == 0 {
+		return nil, nil, fmt.Errorf("%w: empty coupon code", ErrInvalidRequest)
+	}
+	if len(codes) > MaxStackedCodes {
+		return nil, nil, fmt.Errorf("%w: too many coupon codes: got %d, want at most %d", ErrInvalidRequest, len(codes), MaxStackedCodes)
+	}
+
+	var candidates []candidate
+	var dropped []DroppedCoupon
+	for _, code := range codes {
+		coupon, result, err := s.evaluate(basket, code, customer, now, nil)
+		if err != nil && !notApplicable(err) {
+			return nil, nil, err
+		}
+		if err != nil {
+			dropped = append(dropped, dropCoupon(code, err))
+			continue
+		}
+		candidates = append(candidates, candidate{coupon: coupon, basket: result})
+	}
+
+	// Higher priority coupons are applied first; the stable sort keeps the
+	// submitted order for equal priorities.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].coupon.Priority > candidates[j].coupon.Priority
+	})
+
+	return candidates, dropped, nil
+}
+
+// holdAll reserves every chosen coupon and charges its applied discount to
+// its campaign. If a coupon limit or campaign budget is reached everything
+// held so far is given back and the coupon is returned as failed.
+func (s *Service) holdAll(chosen []candidate, applied []AppliedCoupon, customerID string, now time.Time) ([]Reservation, *DroppedCoupon, error) {
+	var reservations []Reservation
+	for i, c := range chosen {
+		reservation := Reservation{
+			ID:              uuid.NewString(),
+			Code:            c.coupon.Code,
+			CampaignID:      c.coupon.CampaignID,
+			CustomerID:      customerID,
+			DiscountAmount:  applied[i].DiscountAmount,
+			ReservedAt:      now,
+			ExpiresAt:       now.Add(s.reservationTTL),
+			CouponVersionID: applied[i].VersionID,
+		}
+		err := s.ledger.Reserve(reservation, c.coupon.MaxRedemptions, c.coupon.MaxPerCustomer)
+		if err == nil {
+			if err = s.spend(reservation.CampaignID, reservation.DiscountAmount, now); err != nil {
+				err = errors.Join(err, s.ledger.Release(reservation.ID))
+			}
+		}
+		if err == nil {
+			reservations = append(reservations, reservation)
+			continue
+		}
+
+		for _, r := range reservations {
+			if rollbackErr := errors.Join(s.ledger.Release(r.ID), s.refund(r.CampaignID, r.DiscountAmount, now)); rollbackErr != nil {
+				return nil, nil, rollbackErr
+			}
+		}
+		if droppable(err) {
+			drop := dropCoupon(c.coupon.Code, err)
+			return nil, &drop, nil
+		}
+		return nil, nil, err
+	}
+	return reservations, nil, nil
+}
+
+// droppable reports whether a coupon that failed to redeem can be left out
+// of the combination instead of failing the whole application.
+func droppable(err error) bool {
+	return errors.Is(err, ErrRedemptionLimitReached) ||
+		errors.Is(err, ErrCustomerLimitReached) ||
+		errors.Is(err, ErrCampaignPaused) ||
+		errors.Is(err, ErrCampaignBudgetExhausted)
+}
+
+// resolve picks the allowed combination with the largest total discount.
+// Ties go to the combination with fewer coupons. Candidates must be sorted
+// by priority.
+func resolve(candidates []candidate) ([]candidate, []DroppedCoupon) {
+	var best []candidate
+	bestDiscount := int64(-1)
+
+	for _, set := range combinations(candidates) {
+		result, _ := stack(set)
+		discount := result.DiscountAmount.Amount
+		if discount > bestDiscount || (discount == bestDiscount && len(set) < len(best)) {
+			best, bestDiscount = set, discount
+		}
+	}
+
+	var dropped []DroppedCoupon
+	for _, c := range candidates {
+		if !containsCode(best, c.coupon.Code) {
+			dropped = append(dropped, dropCoupon(c.coupon.Code, dropReason(c, best)))
+		}
+	}
+	return best, dropped
+}
+
+// combinations returns every set of candidates that can be applied
+// together, keeping the candidate order within each set.
+func combinations(candidates []candidate) [][]candidate {
+	var sets [][]candidate
+	for mask := 1; mask < 1<<len(candidates); mask++ {
+		var set []candidate
+		for i, c := range candidates {
+			if mask&(1<<i) != 0 {
+				set = append(set, c)
+			}
+		}
+		if stackable(set) {
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+// stackable reports whether the coupons may be applied together.
+func stackable(set []candidate) bool {
+	if len(set) == 1 {
+		return true
+	}
+	groups := make(map[string]bool)
+	for _, c := range set {
+		if !c.coupon.Stackable {
+			return false
+		}
+		if group := c.coupon.ExclusivityGroup; group != "" {
+			if groups[group] {
+				return false
+			}
+			groups[group] = true
+		}
+	}
+	return true
+}
+
+// stack applies the coupons of the set one after another in priority
+// order. Each coupon is calculated on what the coupons before it left of
+// every line and of the shipping cost, so the line discounts add up to the
+// basket discount. The applied amounts are returned in set order.
+func stack(set []candidate) (Basket, []Money) {
+	result := *set[0].basket
+	result.Items = make([]LineItem, len(set[0].basket.Items))
+	copy(result.Items, set[0].basket.Items)
+	zero := Money{Currency: result.Currency}
+	for i := range result.Items {
+		result.Items[i].Discount = zero
+	}
+
+	value, shipping := result.Value, result.ShippingCost
+	amounts := make([]Money, len(set))
+	total := zero
+	for i, c := range set {
+		// The remaining basket shares the line items with the result, so
+		// the coupon adds its line discounts to them
+		remaining := result
+		remaining.Value, remaining.ShippingCost = value, shipping
+		amount, err := applyDiscount(c.coupon, &remaining)
+		if err != nil {
+			// The coupon was applied to the same lines on its own
+			amount = zero
+		}
+		if c.coupon.Type == DiscountFreeShipping {
+			shipping = shipping.Sub(amount)
+		} else {
+			value = value.Sub(amount)
+		}
+		amounts[i] = amount
+		total = total.Add(amount)
+	}
+
+	result.DiscountAmount = total
+	return result, amounts
+}
+
+// combine builds the application result for the chosen coupons.
+func combine(basket Basket, chosen []candidate, dropped []DroppedCoupon) *Application {
+	if len(chosen) == 0 {
+		basket.OriginalValue = basket.Value
+		basket.FinalValue = basket.Value.Add(basket.ShippingCost)
+		return &Application{Basket: basket, Applied: []AppliedCoupon{}, Dropped: dropped}
+	}
+
+	result, amounts := stack(chosen)
+	result.AppliedDiscount = 0
+	result.CouponCode = ""
+	result.CouponVersionID = ""
+	result.ExchangeRate = nil
+
+	applied := make([]AppliedCoupon, 0, len(chosen))
+	for i, c := range chosen {
+		applied = append(applied, AppliedCoupon{
+			Code:           c.coupon.Code,
+			VersionID:      c.basket.CouponVersionID,
+			DiscountAmount: amounts[i],
+			ExchangeRate:   c.basket.ExchangeRate,
+		})
+	}
+	result.FinalValue = result.Value.Add(result.ShippingCost).Sub(result.DiscountAmount)
+
+	return &Application{
+		Basket:  result,
+		Applied: applied,
+		Dropped: dropped,
+	}
+}
+
+// dropReason explains why a valid coupon is not part of the chosen set.
+func dropReason(c candidate, chosen []candidate) error {
+	if !c.coupon.Stackable {
+		return fmt.Errorf("%w: coupon cannot be combined with other coupons", ErrCouponNotCombinable)
+	}
+	for _, other := range chosen {
+		if !other.coupon.Stackable {
+			return fmt.Errorf("%w: coupon %s cannot be combined with other coupons", ErrCouponNotCombinable, other.coupon.Code)
+		}
+		if group := c.coupon.ExclusivityGroup; group != "" && group == other.coupon.ExclusivityGroup {
+			return fmt.Errorf("%w: coupon %s is exclusive in group %s", ErrExclusivityConflict, other.coupon.Code, group)
+		}
+	}
+	return fmt.Errorf("%w: coupon does not increase the discount", ErrNoAddedDiscount)
+}
+
+func dropCoupon(code string, err error) DroppedCoupon {
+	return DroppedCoupon{Code: code, Detail: err.Error(), Err: err}
+}
+
+func containsCode(set []candidate, code string) bool {
+	for _, c := range set {
+		if c.coupon.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func withoutCode(candidates []candidate, code string) []candidate {
+	var rest []candidate
+	for _, c := range candidates {
+		if c.coupon.Code != code {
+			rest = append(rest, c)
+		}
+	}
+	return rest
+}
+
+func uniqueCodes(codes []string) []string {
+	seen := make(map[string]bool, len(codes))
+	var unique []string
+	for _, code := range codes {
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		unique = append(unique, code)
+	}
+	return unique
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	. "reviewsch/internal/service/entity"
+	"time"
+)
+
+// ValidateCoupon dry-runs ApplyCoupon: it runs the same evaluation without
+// redeeming the coupon or charging its campaign. Every check is reported;
+// the evaluation stops at the first one that failed, so the checks after
+// it are listed as skipped. A failed check is part of the result; its
+// error is kept in Err.
+func (s *Service) ValidateCoupon(basket Basket, code string, customer Customer) *Eligibility {
+	t := &tracer{}
+	_, result, err := s.evaluate(basket, code, customer, s.now(), t)
+	t.skipRemaining()
+
+	return &Eligibility{
+		Code:     code,
+		Eligible: err == nil,
+		Checks:   t.checks,
+		Basket:   result,
+		Err:      err,
+	}
+}
+
+// tracer records the checks of an evaluation. A nil tracer records nothing.
+type tracer struct {
+	checks []EligibilityCheck
+}
+
+// check records the outcome of a check with the values it compared and
+// returns its error.
+func (t *tracer) check(name string, err error, actual, expected string) error {
+	if t == nil {
+		return err
+	}
+	result := EligibilityCheck{Name: name, Passed: err == nil, Actual: actual, Expected: expected}
+	if err != nil {
+		result.Detail = err.Error()
+	}
+	t.checks = append(t.checks, result)
+	return err
+}
+
+// skipRemaining records the checks the evaluation did not get to as
+// skipped. The checks are recorded in the order of Checks.
+func (t *tracer) skipRemaining() {
+	for _, name := range Checks[len(t.checks):] {
+		t.checks = append(t.checks, EligibilityCheck{Name: name, Skipped: true})
+	}
+}
+
+// amount formats money with its currency for a check, e.g. "40.00 EUR"
+func amount(m Money) string {
+	if m.Currency == "" {
+		return m.String()
+	}
+	return m.String() + " " + m.Currency
+}
+
+// timeWindow describes when the coupon can be used
+func timeWindow(coupon *Coupon) string {
+	from, until := "any time", "no expiry"
+	if !coupon.StartsAt.IsZero() {
+		from = coupon.StartsAt.Format(time.RFC3339)
+	}
+	if !coupon.ExpiresAt.IsZero() {
+		until = coupon.ExpiresAt.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("from %s until %s", from, until)
+}
+
+// assignment describes who may apply the coupon
+func assignment(coupon *Coupon) string {
+	if !coupon.Personal() {
+		return "any customer"
+	}
+	return "assigned customer"
+}
+
+// limits describes the redemption limits of the coupon
+func limits(coupon *Coupon) string {
+	switch {
+	case coupon.MaxRedemptions > 0 && coupon.MaxPerCustomer > 0:
+		return fmt.Sprintf("< %d total, < %d by customer", coupon.MaxRedemptions, coupon.MaxPerCustomer)
+	case coupon.MaxRedemptions > 0:
+		return fmt.Sprintf("< %d total", coupon.MaxRedemptions)
+	case coupon.MaxPerCustomer > 0:
+		return fmt.Sprintf("< %d by customer", coupon.MaxPerCustomer)
+	}
+	return "unlimited"
+}